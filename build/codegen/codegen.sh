@@ -0,0 +1,50 @@
+#!/usr/bin/env bash
+
+# Generates the deepcopy functions, clientset, listers and informers of the
+# storage.rookclient.io API and its CRD manifests.
+#
+# The generators are expected in PATH:
+#   go install k8s.io/code-generator/cmd/{deepcopy-gen,client-gen,lister-gen,informer-gen}@v0.20.0
+#   go install sigs.k8s.io/controller-tools/cmd/controller-gen@v0.16.5
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT_DIR=$(cd "$(dirname "${BASH_SOURCE[0]}")/../.." && pwd)
+GO_PROJECT=github.com/murali-bashyam/rookclient
+APIS_PKG=${GO_PROJECT}/pkg/storageapi/v1
+CLIENT_PKG=${GO_PROJECT}/pkg/client
+BOILERPLATE=${ROOT_DIR}/build/codegen/boilerplate.go.txt
+
+OUTPUT_BASE=$(mktemp -d)
+trap 'rm -rf "${OUTPUT_BASE}"' EXIT
+
+cd "${ROOT_DIR}"
+
+deepcopy-gen --input-dirs "${APIS_PKG}" \
+    --output-file-base zz_generated.deepcopy \
+    --output-base "${OUTPUT_BASE}" --go-header-file "${BOILERPLATE}"
+
+client-gen --clientset-name versioned \
+    --input-base "" --input "${APIS_PKG}" \
+    --output-package "${CLIENT_PKG}/clientset" \
+    --output-base "${OUTPUT_BASE}" --go-header-file "${BOILERPLATE}"
+
+lister-gen --input-dirs "${APIS_PKG}" \
+    --output-package "${CLIENT_PKG}/listers" \
+    --output-base "${OUTPUT_BASE}" --go-header-file "${BOILERPLATE}"
+
+informer-gen --input-dirs "${APIS_PKG}" \
+    --versioned-clientset-package "${CLIENT_PKG}/clientset/versioned" \
+    --listers-package "${CLIENT_PKG}/listers" \
+    --output-package "${CLIENT_PKG}/informers" \
+    --output-base "${OUTPUT_BASE}" --go-header-file "${BOILERPLATE}"
+
+cp "${OUTPUT_BASE}/${APIS_PKG}/zz_generated.deepcopy.go" pkg/storageapi/v1/
+rm -rf pkg/client
+cp -r "${OUTPUT_BASE}/${CLIENT_PKG}" pkg/client
+
+controller-gen crd:crdVersions=v1 paths=./pkg/storageapi/v1/... output:crd:artifacts:config=artifacts/crds
+# The storage policies embed ObjectMeta but are only part of the pool spec.
+rm -f artifacts/crds/storage.rookclient.io_storagepolicy*.yaml
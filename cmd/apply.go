@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Results of applying an object.
+const (
+	applyCreated   string = "created"
+	applyUpdated   string = "updated"
+	applyUnchanged string = "unchanged"
+)
+
+// fileList is a repeatable -f flag.
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// manifests are the storage objects to apply, in the order they are
+// applied.
+type manifests struct {
+	clusters []*storageapiv1.StorageCluster
+	pools    []*storageapiv1.StoragePool
+	volumes  []*storageapiv1.StorageVolume
+}
+
+func invalidManifest(source string, doc int, format string, args ...interface{}) error {
+	return &codedError{
+		code: exitInvalid,
+		err:  fmt.Errorf("Invalid manifest %s document %d: %s", source, doc, fmt.Sprintf(format, args...)),
+	}
+}
+
+// decode appends the storage objects of the multi-document YAML or JSON
+// read from r, objects without namespace are put in namespace.
+func (m *manifests) decode(r io.Reader, source string, namespace string) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for doc := 1; ; doc++ {
+		data, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return invalidManifest(source, doc, "%v", err)
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+			return invalidManifest(source, doc, "%v", err)
+		}
+		if len(typeMeta.APIVersion) == 0 && len(typeMeta.Kind) == 0 {
+			continue
+		}
+		if typeMeta.APIVersion != storageapiv1.SchemeGroupVersion.String() {
+			return invalidManifest(source, doc, "unsupported apiVersion %q, expected %q",
+				typeMeta.APIVersion, storageapiv1.SchemeGroupVersion.String())
+		}
+
+		var meta *metav1.ObjectMeta
+		var validate func() field.ErrorList
+		switch typeMeta.Kind {
+		case "StorageCluster":
+			cluster := &storageapiv1.StorageCluster{}
+			err = yaml.UnmarshalStrict(data, cluster)
+			meta = &cluster.ObjectMeta
+			validate = cluster.Spec.Validate
+			m.clusters = append(m.clusters, cluster)
+		case "StoragePool":
+			pool := &storageapiv1.StoragePool{}
+			err = yaml.UnmarshalStrict(data, pool)
+			meta = &pool.ObjectMeta
+			pool.Spec.SetDefaults()
+			// The pool operations validate against the profile of the flags.
+			validate = func() field.ErrorList { return pool.Spec.Validate(nil) }
+			m.pools = append(m.pools, pool)
+		case "StorageVolume":
+			volume := &storageapiv1.StorageVolume{}
+			err = yaml.UnmarshalStrict(data, volume)
+			meta = &volume.ObjectMeta
+			volume.Spec.SetDefaults()
+			validate = volume.Spec.Validate
+			m.volumes = append(m.volumes, volume)
+		default:
+			return invalidManifest(source, doc, "unsupported kind %q", typeMeta.Kind)
+		}
+		if err != nil {
+			return invalidManifest(source, doc, "%v", err)
+		}
+		if len(meta.Name) == 0 {
+			return invalidManifest(source, doc, "missing metadata.name")
+		}
+		if errs := validate(); len(errs) != 0 {
+			return invalidManifest(source, doc, "%s %s %v", typeMeta.Kind, meta.Name, errs.ToAggregate())
+		}
+		if len(meta.Namespace) == 0 {
+			meta.Namespace = namespace
+		}
+	}
+}
+
+// manifestFiles returns the file itself, else the .yaml, .yml and .json
+// files of the directory in name order.
+func manifestFiles(path string) ([]string, error) {
+	var files []string
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadManifests decodes the manifests of the paths, "-" reads stdin.
+func loadManifests(paths []string, namespace string) (*manifests, error) {
+	m := &manifests{}
+
+	for _, path := range paths {
+		if path == "-" {
+			if err := m.decode(os.Stdin, "stdin", namespace); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files, err := manifestFiles(path)
+		if err != nil {
+			return nil, usageErrorf("Failed to read %s: %v", path, err)
+		}
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, usageErrorf("Failed to read %s: %v", file, err)
+			}
+			if err := m.decode(bytes.NewReader(data), file, namespace); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// clusterChanged reports whether applying desired changes the current
+// storage cluster. The connection settings of external clusters cannot be
+// read back and are always applied.
+func clusterChanged(desired *storageapiv1.StorageCluster, current *storageapiv1.StorageCluster) bool {
+	monitoring := desired.Spec.Monitoring == nil || *desired.Spec.Monitoring
+	return desired.Spec.StorageClusterID != current.Spec.StorageClusterID ||
+		current.Spec.Monitoring == nil || monitoring != *current.Spec.Monitoring ||
+		!equality.Semantic.DeepEqual(desired.Spec.Nodelist, current.Spec.Nodelist) ||
+		desired.Spec.External != nil
+}
+
+// poolChanged reports whether applying desired changes the policies of
+// the current storage pool.
+func poolChanged(desired *storageapiv1.StoragePool, current *storageapiv1.StoragePool) bool {
+	dpolicy := desired.Spec.DurabilityPolicy
+	return dpolicy.FailureDomain != current.Spec.DurabilityPolicy.FailureDomain ||
+		dpolicy.DurabilityClass != current.Spec.DurabilityPolicy.DurabilityClass ||
+		dpolicy.DurabilityLevel != current.Spec.DurabilityPolicy.DurabilityLevel ||
+		desired.Spec.PerfPolicy.IoPerfClass != current.Spec.PerfPolicy.IoPerfClass ||
+		desired.Spec.Quota.Cmp(current.Spec.Quota) != 0 ||
+		desired.Spec.QuotaObjects != current.Spec.QuotaObjects
+}
+
+// volumeChanged reports whether applying desired changes the storage
+// class of the current storage volume.
+func volumeChanged(desired *storageapiv1.StorageVolume, current *storageapiv1.StorageVolume) bool {
+	fstype := desired.Spec.FSType
+	if len(fstype) == 0 {
+		fstype = "ext4"
+	}
+	reclaim := desired.Spec.Reclaim == nil || *desired.Spec.Reclaim
+	allowExpansion := desired.Spec.AllowExpansion == nil || *desired.Spec.AllowExpansion
+	return desired.Spec.ClusterID != current.Spec.ClusterID ||
+		desired.Spec.PoolID != current.Spec.PoolID ||
+		fstype != current.Spec.FSType ||
+		desired.Spec.ReadOnly != current.Spec.ReadOnly ||
+		current.Spec.Reclaim == nil || reclaim != *current.Spec.Reclaim ||
+		current.Spec.AllowExpansion == nil || allowExpansion != *current.Spec.AllowExpansion
+}
+
+// applier creates or updates the storage objects through the clients of
+// the namespace, waiting for the objects the next stage depends on.
+type applier struct {
+	clusters func(namespace string) *storageapiv1.StorageClusters
+	pools    func(namespace string) *storageapiv1.StoragePools
+	volumes  func(namespace string) *storageapiv1.StorageVolumes
+
+	wait    bool
+	timeout time.Duration
+
+	// Count of the applied objects per result.
+	results map[string]int
+}
+
+func (a *applier) report(kind string, meta metav1.ObjectMeta, result string) {
+	if a.results == nil {
+		a.results = map[string]int{}
+	}
+	a.results[result]++
+	fmt.Fprintf(stdout, "%s %s/%s %s \n", kind, meta.Namespace, meta.Name, result)
+}
+
+func (a *applier) applyCluster(ctx context.Context, cluster *storageapiv1.StorageCluster) error {
+	clusters := a.clusters(cluster.ObjectMeta.Namespace)
+	current, err := clusters.GetContext(ctx, cluster.ObjectMeta.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = clusters.CreateContext(ctx, cluster.DeepCopy())
+		if err != nil {
+			return err
+		}
+		a.report("storagecluster", cluster.ObjectMeta, applyCreated)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !clusterChanged(cluster, current) {
+		a.report("storagecluster", cluster.ObjectMeta, applyUnchanged)
+		return nil
+	}
+	_, err = clusters.UpdateContext(ctx, cluster.DeepCopy())
+	if err != nil {
+		return err
+	}
+	a.report("storagecluster", cluster.ObjectMeta, applyUpdated)
+	return nil
+}
+
+func (a *applier) applyPool(ctx context.Context, pool *storageapiv1.StoragePool) error {
+	pools := a.pools(pool.ObjectMeta.Namespace)
+	current, err := pools.GetContext(ctx, pool.ObjectMeta.Name)
+	if apierrors.IsNotFound(err) {
+		err = pools.CreateContext(ctx, pool.DeepCopy())
+		if err != nil {
+			return err
+		}
+		a.report("storagepool", pool.ObjectMeta, applyCreated)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !poolChanged(pool, current) {
+		a.report("storagepool", pool.ObjectMeta, applyUnchanged)
+		return nil
+	}
+	err = pools.UpdateContext(ctx, pool.DeepCopy())
+	if err != nil {
+		return err
+	}
+	a.report("storagepool", pool.ObjectMeta, applyUpdated)
+	return nil
+}
+
+func (a *applier) applyVolume(ctx context.Context, volume *storageapiv1.StorageVolume) error {
+	volumes := a.volumes(volume.ObjectMeta.Namespace)
+	current, err := volumes.GetContext(ctx, volume.ObjectMeta.Name)
+	if apierrors.IsNotFound(err) {
+		_, _, err = volumes.CreateContext(ctx, volume.DeepCopy())
+		if err != nil {
+			return err
+		}
+		a.report("storagevolume", volume.ObjectMeta, applyCreated)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !volumeChanged(volume, current) {
+		a.report("storagevolume", volume.ObjectMeta, applyUnchanged)
+		return nil
+	}
+	_, err = volumes.UpdateContext(ctx, volume.DeepCopy())
+	if err != nil {
+		return err
+	}
+	a.report("storagevolume", volume.ObjectMeta, applyUpdated)
+	return nil
+}
+
+// apply applies the clusters, then the pools, then the volumes. Unless
+// the next stage is empty, the clusters are waited for until ready before
+// the pools are applied, and the pools before the volumes.
+func (a *applier) apply(ctx context.Context, m *manifests) error {
+	for _, cluster := range m.clusters {
+		if err := a.applyCluster(ctx, cluster); err != nil {
+			return fmt.Errorf("Failed to apply storage cluster %s: %w", cluster.ObjectMeta.Name, err)
+		}
+	}
+	if a.wait && (len(m.pools) != 0 || len(m.volumes) != 0) {
+		for _, cluster := range m.clusters {
+			_, err := a.clusters(cluster.ObjectMeta.Namespace).WaitForClusterReady(ctx, cluster.ObjectMeta.Name,
+				storageapiv1.WaitOptions{Timeout: a.timeout})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pool := range m.pools {
+		if err := a.applyPool(ctx, pool); err != nil {
+			return fmt.Errorf("Failed to apply storage pool %s: %w", pool.ObjectMeta.Name, err)
+		}
+	}
+	if a.wait && len(m.volumes) != 0 {
+		for _, pool := range m.pools {
+			_, err := a.pools(pool.ObjectMeta.Namespace).WaitForPoolReady(ctx, pool.ObjectMeta.Name,
+				storageapiv1.WaitOptions{Timeout: a.timeout})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, volume := range m.volumes {
+		if err := a.applyVolume(ctx, volume); err != nil {
+			return fmt.Errorf("Failed to apply storage volume %s: %w", volume.ObjectMeta.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *applier) printSummary() {
+	fmt.Fprintf(stdout, "%d created, %d updated, %d unchanged \n",
+		a.results[applyCreated], a.results[applyUpdated], a.results[applyUnchanged])
+}
+
+func applyCommand(args []string) error {
+	var o commonOptions
+	var files fileList
+	var wait bool
+	var timeout time.Duration
+
+	fs := newFlagSet("apply")
+	o.addFlags(fs)
+	fs.Var(&files, "f", "Manifest file or directory of manifests to apply, \"-\" reads stdin, repeatable")
+	fs.BoolVar(&wait, "wait", true, "Wait for the clusters and pools to become ready before applying their dependents")
+	fs.DurationVar(&timeout, "timeout", 30*time.Minute, "Timeout of each wait")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return usageErrorf("Missing -f")
+	}
+	m, err := loadManifests(files, o.namespace)
+	if err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	a := &applier{
+		clusters: c.StorageClusters,
+		pools:    c.StoragePools,
+		volumes:  c.StorageVolumes,
+		wait:     wait,
+		timeout:  timeout,
+	}
+	err = a.apply(context.Background(), m)
+	a.printSummary()
+	return err
+}
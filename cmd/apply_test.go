@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const testManifests string = `apiVersion: storage.rookclient.io/v1
+kind: StorageVolume
+metadata:
+  name: vol1
+spec:
+  volumetype: block
+  clusterid: rook-ceph
+  pool: bpool1
+  reclaim: true
+---
+# The pool the volume is carved from.
+apiVersion: storage.rookclient.io/v1
+kind: StoragePool
+metadata:
+  name: bpool1
+spec:
+  clusterid: rook-ceph
+  durabilitypolicy:
+    failuredomain: host
+    durabilityclass: replicated
+    redundancylevel: normal
+---
+apiVersion: storage.rookclient.io/v1
+kind: StorageCluster
+metadata:
+  name: rook-ceph
+  namespace: rook-ceph
+spec:
+  monitoring: true
+`
+
+func TestLoadManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "storage.yaml"), []byte(testManifests), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600)
+
+	m, err := loadManifests([]string{dir}, "rook-ceph")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.clusters) != 1 || len(m.pools) != 1 || len(m.volumes) != 1 {
+		t.Fatalf("expected 1 cluster, pool and volume, got %d %d %d", len(m.clusters), len(m.pools), len(m.volumes))
+	}
+	if m.volumes[0].ObjectMeta.Namespace != "rook-ceph" {
+		t.Errorf("expected default namespace, got %q", m.volumes[0].ObjectMeta.Namespace)
+	}
+	if m.pools[0].Spec.DurabilityPolicy.DurabilityLevel != storageapiv1.DurabilityLevelNormal {
+		t.Errorf("unexpected pool spec %+v", m.pools[0].Spec)
+	}
+}
+
+func TestDecodeInvalidManifests(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		message  string
+	}{
+		{
+			name:     "unsupported kind",
+			manifest: "apiVersion: storage.rookclient.io/v1\nkind: StorageSnapshot\nmetadata:\n  name: s1\n",
+			message:  "unsupported kind",
+		},
+		{
+			name:     "unsupported apiVersion",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c1\n",
+			message:  "unsupported apiVersion",
+		},
+		{
+			name:     "unknown field",
+			manifest: "apiVersion: storage.rookclient.io/v1\nkind: StoragePool\nmetadata:\n  name: p1\nspec:\n  size: 3\n",
+			message:  "unknown field",
+		},
+		{
+			name:     "invalid spec",
+			manifest: "apiVersion: storage.rookclient.io/v1\nkind: StorageVolume\nmetadata:\n  name: v1\nspec:\n  volumetype: file\n",
+			message:  "[spec.volumetype: Unsupported value: \"file\": supported values: \"block\", spec.clusterid: Required value, spec.pool: Required value]",
+		},
+		{
+			name:     "missing name",
+			manifest: "apiVersion: storage.rookclient.io/v1\nkind: StorageCluster\nspec: {}\n",
+			message:  "missing metadata.name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &manifests{}
+			err := m.decode(strings.NewReader(tt.manifest), "test.yaml", "rook-ceph")
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Fatalf("expected error containing %q, got %v", tt.message, err)
+			}
+			if exitCode(err) != exitInvalid {
+				t.Errorf("expected exit code %d, got %d", exitInvalid, exitCode(err))
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	rookclnt := rookfake.NewSimpleClientset()
+	kubeclnt := kubefake.NewSimpleClientset()
+	newApplier := func() *applier {
+		return &applier{
+			clusters: func(namespace string) *storageapiv1.StorageClusters {
+				return &storageapiv1.StorageClusters{Namespace: namespace, Client: rookclnt, KubeClient: kubeclnt}
+			},
+			pools: func(namespace string) *storageapiv1.StoragePools {
+				return &storageapiv1.StoragePools{Namespace: namespace, Client: rookclnt}
+			},
+			volumes: func(namespace string) *storageapiv1.StorageVolumes {
+				return &storageapiv1.StorageVolumes{Namespace: namespace, Client: rookclnt, KubeClient: kubeclnt}
+			},
+		}
+	}
+	load := func() *manifests {
+		m := &manifests{}
+		if err := m.decode(strings.NewReader(testManifests), "test.yaml", "rook-ceph"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return m
+	}
+
+	a := newApplier()
+	if err := a.apply(context.TODO(), load()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.results[applyCreated] != 3 {
+		t.Errorf("expected 3 objects created, got %v", a.results)
+	}
+	order := []string{"storagecluster rook-ceph/rook-ceph", "storagepool rook-ceph/bpool1", "storagevolume rook-ceph/vol1"}
+	last := -1
+	for _, line := range order {
+		index := strings.Index(out.String(), line)
+		if index <= last {
+			t.Errorf("expected %q after the previous stage in %q", line, out.String())
+		}
+		last = index
+	}
+
+	a = newApplier()
+	if err := a.apply(context.TODO(), load()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.results[applyUnchanged] != 3 {
+		t.Errorf("expected 3 objects unchanged, got %v", a.results)
+	}
+
+	m := load()
+	m.pools[0].Spec.DurabilityPolicy.DurabilityLevel = storageapiv1.DurabilityLevelHigh
+	a = newApplier()
+	if err := a.apply(context.TODO(), m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.results[applyUpdated] != 1 || a.results[applyUnchanged] != 2 {
+		t.Errorf("expected 1 object updated, got %v", a.results)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	rookclnt := rookfake.NewSimpleClientset()
+	kubeclnt := kubefake.NewSimpleClientset()
+	p := &planner{
+		clusters: func(namespace string) *storageapiv1.StorageClusters {
+			return &storageapiv1.StorageClusters{Namespace: namespace, Client: rookclnt, KubeClient: kubeclnt}
+		},
+		pools: func(namespace string) *storageapiv1.StoragePools {
+			return &storageapiv1.StoragePools{Namespace: namespace, Client: rookclnt}
+		},
+		volumes: func(namespace string) *storageapiv1.StorageVolumes {
+			return &storageapiv1.StorageVolumes{Namespace: namespace, Client: rookclnt, KubeClient: kubeclnt}
+		},
+	}
+	m := &manifests{}
+	if err := m.decode(strings.NewReader(testManifests), "test.yaml", "rook-ceph"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.pools("rook-ceph").Create(m.pools[0].DeepCopy()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rookclnt.ClearActions()
+
+	m.pools[0].Spec.DurabilityPolicy.DurabilityLevel = storageapiv1.DurabilityLevelHigh
+	if err := p.plan(context.TODO(), m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.results[storageapiv1.PlanActionCreate] != 2 || p.results[storageapiv1.PlanActionUpdate] != 1 {
+		t.Errorf("expected 2 objects to create and 1 to update, got %v", p.results)
+	}
+	for _, line := range []string{
+		"storagecluster rook-ceph/rook-ceph: CephCluster rook-ceph/rook-ceph will be created",
+		"storagepool rook-ceph/bpool1: CephBlockPool rook-ceph/bpool1 will be updated",
+		"-     size: 3",
+		"storagevolume rook-ceph/vol1: StorageClass rook-ceph.vol1-block will be created",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q in %q", line, out.String())
+		}
+	}
+	for _, action := range append(rookclnt.Actions(), kubeclnt.Actions()...) {
+		if action.GetVerb() != "get" {
+			t.Errorf("expected only reads, got %v", action)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodeList is a repeatable --node flag, "host" or "host=dev1,dev2".
+type nodeList []storageapiv1.NodeInfo
+
+func (n *nodeList) String() string {
+	var nodes []string
+
+	for _, node := range *n {
+		if len(node.Devices) != 0 {
+			nodes = append(nodes, node.HostName+"="+strings.Join(node.Devices, ","))
+		} else {
+			nodes = append(nodes, node.HostName)
+		}
+	}
+	return strings.Join(nodes, " ")
+}
+
+func (n *nodeList) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	node := storageapiv1.NodeInfo{HostName: strings.TrimSpace(parts[0])}
+	if len(node.HostName) == 0 {
+		return fmt.Errorf("Missing hostname in %q", value)
+	}
+	if len(parts) == 2 {
+		node.Devices = splitList(parts[1])
+	}
+	*n = append(*n, node)
+	return nil
+}
+
+// clusterOptions are the flags of the cluster subcommands.
+type clusterOptions struct {
+	commonOptions
+
+	name       string
+	nodes      nodeList
+	monitoring bool
+	output     string
+
+	externalID        string
+	monEndpoints      string
+	adminKey          string
+	csiNodeKey        string
+	csiProvisionerKey string
+
+	wait    bool
+	timeout time.Duration
+}
+
+func (o *clusterOptions) addSpecFlags(fs *flag.FlagSet) {
+	fs.Var(&o.nodes, "node", "Storage node, \"host\" or \"host=dev1,dev2\", repeat for each node, defaults to all nodes")
+	fs.BoolVar(&o.monitoring, "monitoring", true, "Enable Prometheus monitoring")
+	fs.StringVar(&o.externalID, "external-id", "", "Cluster ID (FSID) of an external Ceph cluster to consume")
+	fs.StringVar(&o.monEndpoints, "mon-endpoints", "", "Monitor endpoints of the external cluster, comma separated ip:port")
+	fs.StringVar(&o.adminKey, "admin-key", "", "Key of the client.admin user of the external cluster")
+	fs.StringVar(&o.csiNodeKey, "csi-rbd-node-key", "", "Key of the CSI rbd node user of the external cluster")
+	fs.StringVar(&o.csiProvisionerKey, "csi-rbd-provisioner-key", "", "Key of the CSI rbd provisioner user of the external cluster")
+}
+
+// applySpec sets the spec fields of the flags given on the command line.
+func (o *clusterOptions) applySpec(fs *flag.FlagSet, spec *storageapiv1.StorageClusterSpec) {
+	if isSet(fs, "node") {
+		spec.Nodelist = o.nodes
+	}
+	if isSet(fs, "monitoring") {
+		monitoring := o.monitoring
+		spec.Monitoring = &monitoring
+	}
+	if isSet(fs, "external-id") {
+		spec.StorageClusterID = o.externalID
+	}
+	if !isSet(fs, "mon-endpoints") && !isSet(fs, "admin-key") &&
+		!isSet(fs, "csi-rbd-node-key") && !isSet(fs, "csi-rbd-provisioner-key") {
+		return
+	}
+	if spec.External == nil {
+		spec.External = &storageapiv1.ExternalClusterSpec{}
+	}
+	if isSet(fs, "mon-endpoints") {
+		spec.External.MonEndpoints = splitList(o.monEndpoints)
+	}
+	if isSet(fs, "admin-key") {
+		spec.External.AdminKey = o.adminKey
+	}
+	if isSet(fs, "csi-rbd-node-key") {
+		spec.External.CSIRBDNodeKey = o.csiNodeKey
+	}
+	if isSet(fs, "csi-rbd-provisioner-key") {
+		spec.External.CSIRBDProvisionerKey = o.csiProvisionerKey
+	}
+}
+
+func clusterCommands() subcommands {
+	return subcommands{
+		"create": createClusterCommand,
+		"get":    getClusterCommand,
+		"list":   listClusterCommand,
+		"update": updateClusterCommand,
+		"delete": deleteClusterCommand,
+	}
+}
+
+func clusterRow(cluster *storageapiv1.StorageCluster) []string {
+	return []string{cluster.ObjectMeta.Name, string(cluster.Status.Phase), string(cluster.Status.State),
+		cluster.Status.Message}
+}
+
+const clusterHeader string = "NAME\tPHASE\tSTATE\tMESSAGE"
+
+func createClusterCommand(args []string) error {
+	var o clusterOptions
+
+	fs := newFlagSet("cluster create")
+	o.addFlags(fs)
+	o.addSpecFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage cluster")
+	fs.BoolVar(&o.wait, "wait", false, "Wait for the storage cluster to become ready")
+	fs.DurationVar(&o.timeout, "timeout", 30*time.Minute, "Timeout of --wait")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster := &storageapiv1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.name,
+			Namespace: o.namespace,
+		},
+		Spec: storageapiv1.StorageClusterSpec{
+			Monitoring: &o.monitoring,
+		},
+	}
+	o.applySpec(fs, &cluster.Spec)
+	clusters := c.StorageClusters(o.namespace)
+	_, err = clusters.Create(cluster)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage cluster %s created \n", o.name)
+	if !o.wait {
+		return nil
+	}
+	_, err = clusters.WaitForClusterReady(context.Background(), o.name, storageapiv1.WaitOptions{
+		Timeout: o.timeout,
+		Progress: func(status storageapiv1.WaitStatus) {
+			fmt.Fprintf(stderr, "Waiting for storage cluster %s, state %s, phase %s message %s \n",
+				o.name, status.State, status.Phase, status.Message)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage cluster %s is ready \n", o.name)
+	return nil
+}
+
+func getClusterCommand(args []string) error {
+	var o clusterOptions
+
+	fs := newFlagSet("cluster get")
+	o.addFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage cluster")
+	fs.StringVar(&o.output, "o", "table", "Output format, table|yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	cluster, err := c.StorageClusters(o.namespace).Get(o.name)
+	if err != nil {
+		return err
+	}
+	return printObject(o.output, cluster, clusterHeader, clusterRow(cluster))
+}
+
+func listClusterCommand(args []string) error {
+	var o clusterOptions
+	var selector string
+
+	fs := newFlagSet("cluster list")
+	o.addFlags(fs)
+	fs.StringVar(&selector, "l", "", "Label selector of the storage clusters")
+	fs.StringVar(&o.output, "o", "table", "Output format, table|yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	clusters, err := c.StorageClusters(o.namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for i := range clusters.Items {
+		rows = append(rows, clusterRow(&clusters.Items[i]))
+	}
+	return printObject(o.output, clusters, clusterHeader, rows...)
+}
+
+func updateClusterCommand(args []string) error {
+	var o clusterOptions
+
+	fs := newFlagSet("cluster update")
+	o.addFlags(fs)
+	o.addSpecFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage cluster")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	clusters := c.StorageClusters(o.namespace)
+	cluster, err := clusters.Get(o.name)
+	if err != nil {
+		return err
+	}
+	o.applySpec(fs, &cluster.Spec)
+	_, err = clusters.Update(cluster)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage cluster %s updated \n", o.name)
+	return nil
+}
+
+func deleteClusterCommand(args []string) error {
+	var o clusterOptions
+
+	fs := newFlagSet("cluster delete")
+	o.addFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage cluster")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	err = c.StorageClusters(o.namespace).Delete(o.name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage cluster %s deleted \n", o.name)
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	storageapi "github.com/murali-bashyam/rookclient/pkg/storageapi"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	defaultNamespace string = "rook-ceph"
+
+	kubeconfigUsage string = "Path of the kubeconfig file, defaults to $KUBECONFIG, ~/.kube/config or the in-cluster config"
+)
+
+// profileOptions select the mapping profile of the storage pool
+// policies, from a file or a ConfigMap.
+type profileOptions struct {
+	file      string
+	configMap string
+}
+
+func (o *profileOptions) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.file, "mapping-profile", "", "File of the durability and performance mapping profile")
+	fs.StringVar(&o.configMap, "mapping-profile-configmap", "",
+		"Namespace/name of the ConfigMap holding the mapping profile in "+storageapiv1.MappingProfileKey)
+}
+
+// validate checks the profile flags before any config is loaded.
+func (o *profileOptions) validate() error {
+	if len(o.file) != 0 && len(o.configMap) != 0 {
+		return usageErrorf("Only one of --mapping-profile and --mapping-profile-configmap can be set")
+	}
+	if len(o.configMap) != 0 {
+		parts := strings.Split(o.configMap, "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return usageErrorf("Invalid --mapping-profile-configmap %q, expected namespace/name", o.configMap)
+		}
+	}
+	return nil
+}
+
+// load returns the selected mapping profile, nil if none was selected.
+func (o *profileOptions) load(ctx context.Context, config *restclient.Config) (*storageapiv1.MappingProfile, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	} else if len(o.file) != 0 {
+		profile, err := storageapiv1.LoadMappingProfileFile(o.file)
+		if err != nil {
+			return nil, configError(err)
+		}
+		return profile, nil
+	} else if len(o.configMap) == 0 {
+		return nil, nil
+	}
+	parts := strings.Split(o.configMap, "/")
+	kubeclnt, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, configError(err)
+	}
+	profile, err := storageapiv1.LoadMappingProfileConfigMap(ctx, kubeclnt, parts[0], parts[1])
+	if err != nil {
+		return nil, configError(err)
+	}
+	return profile, nil
+}
+
+// loadConfig returns the client config of the kubeconfig file, else of the
+// files in $KUBECONFIG, else of ~/.kube/config, else of the pod the
+// command runs in.
+func loadConfig(kubeconfig string) (*restclient.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if len(kubeconfig) != 0 {
+		rules.ExplicitPath = kubeconfig
+	} else if len(os.Getenv(clientcmd.RecommendedConfigPathEnvVar)) == 0 {
+		if _, err := os.Stat(clientcmd.RecommendedHomeFile); err != nil {
+			config, err := restclient.InClusterConfig()
+			if err != nil {
+				return nil, configError(fmt.Errorf("Failed to find a kubeconfig in $%s, %s or the pod: %v",
+					clientcmd.RecommendedConfigPathEnvVar, clientcmd.RecommendedHomeFile, err))
+			}
+			return config, nil
+		}
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules,
+		&clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, configError(fmt.Errorf("Failed to load kubeconfig: %v", err))
+	}
+	return config, nil
+}
+
+// commonOptions are the flags shared by the cluster, pool and volume
+// commands.
+type commonOptions struct {
+	kubeconfig string
+	namespace  string
+	profile    profileOptions
+}
+
+func (o *commonOptions) addFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", kubeconfigUsage)
+	fs.StringVar(&o.namespace, "namespace", defaultNamespace, "Namespace of the storage cluster")
+	o.profile.addFlags(fs)
+}
+
+func (o *commonOptions) clientset() (*storageapi.Clientset, error) {
+	if err := o.profile.validate(); err != nil {
+		return nil, err
+	}
+	config, err := loadConfig(o.kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := o.profile.load(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	c, err := storageapi.NewForConfig(config)
+	if err != nil {
+		return nil, configError(err)
+	}
+	return c.WithProfile(profile), nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes of the command line, scripts can tell the failures apart.
+const (
+	exitOK            int = 0
+	exitError         int = 1 // any failure not listed below
+	exitUsage         int = 2 // invalid command line
+	exitConfig        int = 3 // no usable kubeconfig or in-cluster config
+	exitNotFound      int = 4 // the object does not exist
+	exitAlreadyExists int = 5 // the object already exists
+	exitInvalid       int = 6 // the object was rejected as invalid or conflicting
+	exitTimeout       int = 7 // the wait for the object timed out
+)
+
+// codedError is an error carrying the exit code of the command.
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return &codedError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func configError(err error) error {
+	return &codedError{code: exitConfig, err: err}
+}
+
+// exitCode returns the exit code of the command failing with err.
+func exitCode(err error) int {
+	var coded *codedError
+	var timeout *storageapiv1.WaitTimeoutError
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.As(err, &timeout):
+		return exitTimeout
+	case apierrors.IsNotFound(err):
+		return exitNotFound
+	case apierrors.IsAlreadyExists(err):
+		return exitAlreadyExists
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err), apierrors.IsConflict(err),
+		errors.Is(err, storageapiv1.ErrInvalidPolicy), errors.Is(err, storageapiv1.ErrUnsupportedTransition):
+		return exitInvalid
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return exitTimeout
+	}
+	return exitError
+}
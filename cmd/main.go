@@ -1,246 +1,183 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"time"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
-	storageapi "github.com/murali-bashyam/rookclient/pkg/storageapi"
-	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	restclient "k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
 )
 
-const (
-	ROOK_NAMESPACE    string = "rook-ceph"
-	CLUSTER_NAME      string = "rook-ceph"
-	STORAGE_POOL_NAME string = "bpool1"
-	KUBE_CONFIG       string = "/home/mbcoder/kubeconfig"
-)
+const usage string = `Usage: rookclient <command> [flags]
 
-func createCluster(c *storageapi.Clientset, ns string, clustername string) error {
-	cluster := &storageapiv1.StorageCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      clustername,
-			Namespace: ns,
-		},
-	}
-	cluster, err := c.StorageClusters(ns).Create(cluster)
-	if err == nil {
-		fmt.Printf("Storage cluster created, status %s, phase %s message %s \n",
-			cluster.Status.State,
-			cluster.Status.Phase,
-			cluster.Status.Message)
-	} else {
-		fmt.Printf("Failed to create Storage cluster, status %s, phase %s error %s \n",
-			cluster.Status.State,
-			cluster.Status.Phase,
-			cluster.Status.Message)
-	}
+Commands:
+  cluster create|get|list|update|delete   Manage storage clusters
+  pool    create|get|list|update|delete   Manage storage pools
+  volume  create|get|list|update|delete   Manage storage volumes
+  apply   -f <file|dir>                   Create or update the objects of manifests
+  plan    -f <file|dir>                   Show the changes apply would make
+  operator                                Run the storage operator
+  webhook --tls-cert-file <file> --tls-private-key-file <file>
+                                          Serve the admission webhooks
 
-	return err
-}
+Run "rookclient <command> <subcommand> -h" for the flags of a subcommand.
+`
 
-func isClusterHealthy(c *storageapi.Clientset, ns string, clustername string) (bool, error) {
-	cluster, err := c.StorageClusters(ns).Get(clustername)
-	if err == nil {
-		if cluster.Status.State == storageapiv1.ClusterStateCreated &&
-			cluster.Status.Phase == storageapiv1.ClusterPhaseReady {
-			fmt.Printf("Storage Cluster %s is healthy \n", ns)
-			return true, nil
-		}
-		fmt.Printf("Storage cluster health status %s, phase %s message %s \n",
-			cluster.Status.State,
-			cluster.Status.Phase,
-			cluster.Status.Message)
-	} else {
-		fmt.Printf("Failed to get storage cluster info, %s \n", clustername)
-	}
-	return false, err
-}
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
 
-func isStoragePoolReady(c *storageapi.Clientset, ns string, clustername string, poolname string) (bool, error) {
-	pool, err := c.StoragePools(ns).Get(poolname)
-	if err == nil {
-		if pool != nil {
-			if pool.Status.Phase == storageapiv1.PoolPhaseReady {
-				fmt.Printf("Storage Pool %s is ready \n", poolname)
-				return true, nil
-			}
-			fmt.Printf("Storage pool status %s \n", pool.Status.Phase)
-		}
-	} else {
-		fmt.Printf("Failed to get storage pool info, %s \n", poolname)
-	}
-	return false, err
-}
+	// errHelp is returned when the usage was asked for with -h.
+	errHelp = errors.New("help requested")
+)
 
-func createStoragePool(c *storageapi.Clientset, ns string, clustername string,
-	poolname string) error {
-	dpolicy := storageapiv1.StoragePolicyDurability{
-		FailureDomain:   storageapiv1.FailureDomainHost,
-		DurabilityClass: storageapiv1.DurabilityClassReplicated,
-		DurabilityLevel: storageapiv1.DurabilityLevelNormal,
-	}
+// subcommands maps the subcommands of a command to their handlers.
+type subcommands map[string]func(args []string) error
 
-	pool := &storageapiv1.StoragePool{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      poolname,
-			Namespace: ns,
-		},
-		Spec: storageapiv1.StoragePoolSpec{
-			ClusterID:        clustername,
-			Quota:            0,
-			DurabilityPolicy: dpolicy,
-		},
-	}
-	err := c.StoragePools(ns).Create(pool)
-	if err != nil {
-		fmt.Printf("Failed to create storage pool %v \n", err)
-		return err
+func (s subcommands) run(command string, args []string) error {
+	var names []string
+
+	for name := range s {
+		names = append(names, name)
 	}
-	pool, err = c.StoragePools(ns).Get(poolname)
-	if err != nil {
-		fmt.Printf("Failed to get storage pool info %v \n", err)
+	sort.Strings(names)
+	if len(args) == 0 {
+		return usageErrorf("Missing %s subcommand, one of %s", command, strings.Join(names, "|"))
 	}
-	return err
-}
-
-func deleteStoragePool(c *storageapi.Clientset, ns string, poolname string) error {
-	err := c.StoragePools(ns).Delete(poolname)
-	if err != nil {
-		fmt.Printf("Failed to delete storage pool %v \n", err)
+	handler, ok := s[args[0]]
+	if !ok {
+		return usageErrorf("Unknown %s subcommand %q, one of %s", command, args[0], strings.Join(names, "|"))
 	}
-	return err
+	return handler(args[1:])
 }
 
-func deleteStorageCluster(c *storageapi.Clientset, ns string, clustername string) error {
-	err := c.StorageClusters(ns).Delete(clustername)
-	if err != nil {
-		fmt.Printf("Failed to delete storage cluster %v\n", err)
-	}
-	return err
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("rookclient "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
 }
 
-func createBlockStorageVolume(c *storageapi.Clientset, ns string,
-	clustername string, poolname string) error {
-	volume := &storageapiv1.StorageVolume{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      poolname,
-			Namespace: ns,
-		},
-		Spec: storageapiv1.StorageVolumeSpec{
-			VolumeType: storageapiv1.BlockVolume,
-			PoolID:     poolname,
-			ClusterID:  clustername,
-			FSType:     "ext4",
-			ReadOnly:   false,
-			Reclaim:    true,
-		},
+// parseFlags parses the flags of a subcommand, which takes no arguments.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return errHelp
+	} else if err != nil {
+		return &codedError{code: exitUsage, err: err}
 	}
-	volume, sc, err := c.StorageVolumes(ns).Create(volume)
-	if err != nil {
-		fmt.Printf("Failed to create storage volume %v \n", err)
-	} else {
-		fmt.Println("Storage class for block volume : ")
-		fmt.Printf("%s \n", *sc)
+	if fs.NArg() != 0 {
+		return usageErrorf("Unexpected arguments %v", fs.Args())
 	}
-	return err
+	return nil
 }
 
-func main() {
-
-	var kubeconfig *string
-	var config *restclient.Config
-	var incluster bool
-
-	if len(os.Args) > 1 {
-		if os.Args[1] == "incluster" {
-			incluster = true
+// isSet reports whether the flag was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
 		}
+	})
+	return set
+}
+
+func requireName(name string) error {
+	if len(name) == 0 {
+		return usageErrorf("Missing --name")
 	}
+	return nil
+}
 
-	if incluster == false {
-		kubeconfig = flag.String("kubeconfig", KUBE_CONFIG, "kubeconfig file")
-		flag.Parse()
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	var items []string
 
-		kconfig, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
-		if err != nil {
-			log.Fatal("Failed to build config", err)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) != 0 {
+			items = append(items, item)
 		}
-		config = kconfig
-	} else {
-		kconfig, err := restclient.InClusterConfig()
-		if err != nil {
-			log.Fatal("Failed to build config", err)
-		} else {
-			fmt.Println("Successfully built incluster config")
-		}
-		config = kconfig
 	}
+	return items
+}
 
-	storageclnt, err := storageapi.NewForConfig(config)
-	if err != nil {
-		log.Fatal("Failed to build storage clientconfig", err)
+func validateOutput(output string) error {
+	switch output {
+	case "table", "yaml", "json":
+		return nil
 	}
-	err = createCluster(storageclnt, ROOK_NAMESPACE, CLUSTER_NAME)
-	if err != nil {
-		log.Fatal("Failed to create storage cluster ", err)
-	} else {
-		fmt.Printf("Successfully created storage cluster %s \n", CLUSTER_NAME)
-	}
-	for {
-		status, err := isClusterHealthy(storageclnt, ROOK_NAMESPACE, CLUSTER_NAME)
+	return usageErrorf("Invalid output format %q, one of table|yaml|json", output)
+}
+
+// printObject prints obj as YAML or JSON, else prints the rows of the
+// table.
+func printObject(output string, obj interface{}, header string, rows ...[]string) error {
+	switch output {
+	case "yaml":
+		out, err := yaml.Marshal(obj)
 		if err != nil {
-			log.Fatal("Failed to determine health of storage cluster \n", err)
-		} else if status == true {
-			fmt.Printf("Storage cluster is healthy %s \n", CLUSTER_NAME)
-			break
-		} else {
-			fmt.Printf("Waiting for Storage cluster to become healthy %s \n", CLUSTER_NAME)
+			return err
 		}
-		time.Sleep(10 * time.Second)
-	}
-	err = createStoragePool(storageclnt, ROOK_NAMESPACE, CLUSTER_NAME, STORAGE_POOL_NAME)
-	if err != nil {
-		log.Fatal("Failed to create storage pool \n", err)
-	} else {
-		fmt.Printf("Successfully created storage pool %s \n", STORAGE_POOL_NAME)
-	}
-
-	for {
-		status, err := isStoragePoolReady(storageclnt, ROOK_NAMESPACE, CLUSTER_NAME, STORAGE_POOL_NAME)
+		_, err = stdout.Write(out)
+		return err
+	case "json":
+		out, err := json.MarshalIndent(obj, "", "  ")
 		if err != nil {
-			log.Fatal("Failed to determine status of storage pool \n", err)
-		} else if status == true {
-			fmt.Printf("Storage pool is ready %s \n", STORAGE_POOL_NAME)
-			break
-		} else {
-			fmt.Printf("Waiting for Storage pool to become ready %s \n", STORAGE_POOL_NAME)
+			return err
 		}
-		time.Sleep(10 * time.Second)
+		_, err = fmt.Fprintln(stdout, string(out))
+		return err
 	}
-	err = createBlockStorageVolume(storageclnt, ROOK_NAMESPACE, CLUSTER_NAME, STORAGE_POOL_NAME)
-	if err != nil {
-		log.Fatal("Failed to create block storage volume \n", err)
-	} else {
-		fmt.Printf("Successfully created block storage volume \n")
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
 	}
+	return w.Flush()
+}
 
-	if incluster == true {
-		for {
-			status, err := isClusterHealthy(storageclnt, ROOK_NAMESPACE, CLUSTER_NAME)
-			if err != nil {
-				log.Fatal("Failed to determine health of storage cluster ", err)
-			} else if status == true {
-				fmt.Printf("Storage cluster is healthy %s \n", CLUSTER_NAME)
-			} else {
-				fmt.Printf("Waiting for Storage cluster to become healthy %s \n", CLUSTER_NAME)
-			}
-			time.Sleep(10 * time.Second)
+func run(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("Missing command")
+	}
+	switch args[0] {
+	case "cluster":
+		return clusterCommands().run("cluster", args[1:])
+	case "pool":
+		return poolCommands().run("pool", args[1:])
+	case "volume":
+		return volumeCommands().run("volume", args[1:])
+	case "apply":
+		return applyCommand(args[1:])
+	case "plan":
+		return planCommand(args[1:])
+	case "operator":
+		return operatorCommand(args[1:])
+	case "webhook":
+		return webhookCommand(args[1:])
+	case "help", "-h", "-help", "--help":
+		return errHelp
+	}
+	return usageErrorf("Unknown command %q", args[0])
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if err == errHelp {
+		fmt.Fprint(stderr, usage)
+		os.Exit(exitOK)
+	} else if err != nil {
+		fmt.Fprintf(stderr, "Error: %v \n", err)
+		if exitCode(err) == exitUsage {
+			fmt.Fprint(stderr, usage)
 		}
+		os.Exit(exitCode(err))
 	}
 }
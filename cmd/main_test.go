@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExitCode(t *testing.T) {
+	resource := schema.GroupResource{Group: "ceph.rook.io", Resource: "cephblockpools"}
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "success", err: nil, code: exitOK},
+		{name: "usage", err: usageErrorf("Missing --name"), code: exitUsage},
+		{name: "config", err: configError(errors.New("no config")), code: exitConfig},
+		{name: "not found", err: apierrors.NewNotFound(resource, "bpool1"), code: exitNotFound},
+		{name: "already exists", err: apierrors.NewAlreadyExists(resource, "bpool1"), code: exitAlreadyExists},
+		{name: "invalid", err: apierrors.NewBadRequest("invalid pool"), code: exitInvalid},
+		{name: "invalid policy", err: &storageapiv1.InvalidPolicyError{Field: "spec.quota", Value: "-1"}, code: exitInvalid},
+		{name: "unsupported transition", err: &storageapiv1.UnsupportedTransitionError{Kind: "Ceph block pool", Name: "bpool1"}, code: exitInvalid},
+		{name: "timeout", err: &storageapiv1.WaitTimeoutError{Kind: "storage pool", Name: "bpool1"}, code: exitTimeout},
+		{name: "other", err: errors.New("failed"), code: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.err); code != tt.code {
+				t.Errorf("expected exit code %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	stderr = ioutil.Discard
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "no command", args: nil, code: exitUsage},
+		{name: "unknown command", args: []string{"snapshot"}, code: exitUsage},
+		{name: "unknown subcommand", args: []string{"pool", "resize"}, code: exitUsage},
+		{name: "missing name", args: []string{"cluster", "get"}, code: exitUsage},
+		{name: "unknown flag", args: []string{"pool", "get", "--size", "1"}, code: exitUsage},
+		{name: "extra argument", args: []string{"volume", "delete", "--name", "vol1", "vol2"}, code: exitUsage},
+		{name: "missing pool", args: []string{"volume", "create", "--name", "vol1"}, code: exitUsage},
+		{name: "invalid output", args: []string{"pool", "list", "-o", "xml"}, code: exitUsage},
+		{name: "invalid quota", args: []string{"pool", "create", "--name", "bpool1", "--quota", "lots"}, code: exitUsage},
+		{name: "two profiles", args: []string{"pool", "list", "--mapping-profile", "profile.yaml",
+			"--mapping-profile-configmap", "rook-ceph/profile"}, code: exitUsage},
+		{name: "invalid profile configmap", args: []string{"operator", "--mapping-profile-configmap", "profile"}, code: exitUsage},
+		{name: "missing certificate", args: []string{"webhook", "--tls-cert-file", "tls.crt"}, code: exitUsage},
+		{name: "help", args: []string{"pool", "get", "-h"}, code: exitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(tt.args)
+			if tt.code == exitOK {
+				if err != errHelp {
+					t.Errorf("expected help, got %v", err)
+				}
+				return
+			}
+			if code := exitCode(err); code != tt.code {
+				t.Errorf("expected exit code %d, got %d: %v", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func TestNodeList(t *testing.T) {
+	var nodes nodeList
+
+	for _, value := range []string{"node1", "node2=sdb,/dev/sdc"} {
+		if err := nodes.Set(value); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(nodes) != 2 || nodes[0].HostName != "node1" || len(nodes[0].Devices) != 0 {
+		t.Errorf("unexpected nodes %v", nodes)
+	}
+	if nodes[1].HostName != "node2" || len(nodes[1].Devices) != 2 || nodes[1].Devices[1] != "/dev/sdc" {
+		t.Errorf("unexpected nodes %v", nodes)
+	}
+	if err := nodes.Set("=sdb"); err == nil {
+		t.Errorf("expected error for missing hostname")
+	}
+}
+
+const testKubeconfig string = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://%s:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: secret
+`
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	explicit := filepath.Join(dir, "explicit")
+	env := filepath.Join(dir, "env")
+	ioutil.WriteFile(explicit, []byte(fmt.Sprintf(testKubeconfig, "explicit.example.com")), 0600)
+	ioutil.WriteFile(env, []byte(fmt.Sprintf(testKubeconfig, "env.example.com")), 0600)
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", env)
+
+	config, err := loadConfig(explicit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Host != "https://explicit.example.com:6443" {
+		t.Errorf("expected the --kubeconfig file, got host %s", config.Host)
+	}
+	config, err = loadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Host != "https://env.example.com:6443" {
+		t.Errorf("expected the $KUBECONFIG file, got host %s", config.Host)
+	}
+	_, err = loadConfig(filepath.Join(dir, "missing"))
+	if exitCode(err) != exitConfig {
+		t.Errorf("expected config error, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	storageclient "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions"
+	"github.com/murali-bashyam/rookclient/pkg/controller"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2/klogr"
+)
+
+const (
+	resyncPeriod time.Duration = 5 * time.Minute
+
+	// Address serving /healthz, /readyz and /metrics.
+	probeAddress string = ":8080"
+
+	// Lease held by the replica running the controllers.
+	leaseName     string        = "rookclient-operator"
+	leaseDuration time.Duration = 15 * time.Second
+	renewDeadline time.Duration = 10 * time.Second
+	retryPeriod   time.Duration = 2 * time.Second
+)
+
+// operatorIdentity returns the leader election identity and namespace of
+// the lease, set from the pod by the deployment.
+func operatorIdentity() (string, string, error) {
+	identity := os.Getenv("POD_NAME")
+	if len(identity) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", "", err
+		}
+		identity = hostname
+	}
+	namespace := os.Getenv("POD_NAMESPACE")
+	if len(namespace) == 0 {
+		namespace = defaultNamespace
+	}
+	return identity, namespace, nil
+}
+
+// operatorCommand runs the operator with the config of the kubeconfig flag,
+// else the default kubeconfig resolution.
+func operatorCommand(args []string) error {
+	var kubeconfig string
+	var profileOpts profileOptions
+
+	fs := newFlagSet("operator")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", kubeconfigUsage)
+	profileOpts.addFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := profileOpts.validate(); err != nil {
+		return err
+	}
+	config, err := loadConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	profile, err := profileOpts.load(context.Background(), config)
+	if err != nil {
+		return err
+	}
+	return runOperator(config, profile)
+}
+
+// runOperator reconciles the storage.rookclient.io objects of all
+// namespaces while holding the operator lease, until the process is
+// terminated or the lease is lost. The storage pools are mapped with
+// profile, nil uses the default one.
+func runOperator(config *restclient.Config, profile *storageapiv1.MappingProfile) error {
+	storageclnt, err := storageclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	rookclnt, err := rookclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	kubeclnt, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	identity, namespace, err := operatorIdentity()
+	if err != nil {
+		return err
+	}
+
+	log := klogr.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	var terminated int32
+	go func() {
+		sig := <-signals
+		log.Info("Stopping operator", "signal", sig.String())
+		atomic.StoreInt32(&terminated, 1)
+		cancel()
+	}()
+
+	storageInformers := storageinformers.NewSharedInformerFactory(storageclnt, resyncPeriod)
+	cephInformers := storageapiv1.NewSharedInformerFactory(rookclnt, "", resyncPeriod)
+	cephInformers.Profile = profile
+	kubeInformers := kubeinformers.NewSharedInformerFactory(kubeclnt, resyncPeriod)
+	clusterController := controller.NewStorageClusterController(storageclnt, rookclnt, kubeclnt,
+		storageInformers.Storage().V1().StorageClusters(), cephInformers.StorageClusters(), log)
+	poolController := controller.NewStoragePoolController(storageclnt, rookclnt, kubeclnt,
+		storageInformers.Storage().V1().StoragePools(), cephInformers.StoragePools(), log)
+	volumeController := controller.NewStorageVolumeController(storageclnt, kubeclnt,
+		storageInformers.Storage().V1().StorageVolumes(), kubeInformers.Core().V1().PersistentVolumeClaims(), log)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	err = controller.RegisterMetrics(registry, storageInformers.Storage().V1().StorageClusters().Lister(),
+		storageInformers.Storage().V1().StoragePools().Lister())
+	if err != nil {
+		return err
+	}
+
+	// Replicas waiting for the lease are ready, the leader once its
+	// caches are synced.
+	var leading int32
+	watchdog := leaderelection.NewLeaderHealthzAdaptor(renewDeadline)
+	ready := func() bool {
+		if atomic.LoadInt32(&leading) == 0 {
+			return true
+		}
+		return clusterController.HasSynced() && poolController.HasSynced() && volumeController.HasSynced()
+	}
+	server := &http.Server{
+		Addr:    probeAddress,
+		Handler: probeHandler(registry, watchdog, ready),
+	}
+	serveErrs := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErrs <- err
+			cancel()
+		}
+	}()
+	defer server.Close()
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseName,
+			Namespace: namespace,
+		},
+		Client: kubeclnt.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	controllerErrs := make(chan error, 1)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		WatchDog:        watchdog,
+		Name:            leaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Info("Acquired lease", "lease", namespace+"/"+leaseName, "identity", identity)
+				atomic.StoreInt32(&leading, 1)
+				storageInformers.Start(ctx.Done())
+				cephInformers.Start(ctx.Done())
+				kubeInformers.Start(ctx.Done())
+
+				errs := make(chan error, 3)
+				go func() {
+					errs <- clusterController.Run(ctx, 2)
+				}()
+				go func() {
+					errs <- poolController.Run(ctx, 2)
+				}()
+				go func() {
+					errs <- volumeController.Run(ctx, 2)
+				}()
+				var runErr error
+				for i := 0; i < 3; i++ {
+					if err := <-errs; err != nil && runErr == nil {
+						runErr = err
+						cancel()
+					}
+				}
+				controllerErrs <- runErr
+			},
+			OnStoppedLeading: func() {
+				// The process exits rather than racing the new leader.
+				cancel()
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	elector.Run(ctx)
+
+	if atomic.LoadInt32(&leading) == 1 {
+		if err := <-controllerErrs; err != nil {
+			return err
+		}
+	}
+	select {
+	case err := <-serveErrs:
+		return fmt.Errorf("Failed to serve probes: %v", err)
+	default:
+	}
+	if atomic.LoadInt32(&terminated) == 0 {
+		return fmt.Errorf("Lost lease %s/%s", namespace, leaseName)
+	}
+	return nil
+}
+
+// probeHandler serves the liveness, readiness and Prometheus endpoints.
+func probeHandler(registry *prometheus.Registry, watchdog *leaderelection.HealthzAdaptor,
+	ready func() bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := watchdog.Check(r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready() {
+			http.Error(w, "caches not synced", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return mux
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var planVerbs = map[storageapiv1.PlanAction]string{
+	storageapiv1.PlanActionCreate:  "will be created",
+	storageapiv1.PlanActionUpdate:  "will be updated",
+	storageapiv1.PlanActionReplace: "will be replaced",
+	storageapiv1.PlanActionNone:    "unchanged",
+}
+
+// planner prints the Ceph objects apply would send for the manifests,
+// without changing anything.
+type planner struct {
+	clusters func(namespace string) *storageapiv1.StorageClusters
+	pools    func(namespace string) *storageapiv1.StoragePools
+	volumes  func(namespace string) *storageapiv1.StorageVolumes
+
+	// Count of the planned objects per action.
+	results map[storageapiv1.PlanAction]int
+}
+
+// planOrUpdate returns the create plan, or the update plan when the object
+// already exists.
+func planOrUpdate(create func() (*storageapiv1.Plan, error), update func() (*storageapiv1.Plan, error)) (*storageapiv1.Plan, error) {
+	plan, err := create()
+	if apierrors.IsAlreadyExists(err) {
+		return update()
+	}
+	return plan, err
+}
+
+func (p *planner) report(kind string, meta metav1.ObjectMeta, plan *storageapiv1.Plan) {
+	if p.results == nil {
+		p.results = map[storageapiv1.PlanAction]int{}
+	}
+	p.results[plan.Action]++
+	name := plan.Name
+	if len(plan.Namespace) != 0 {
+		name = plan.Namespace + "/" + plan.Name
+	}
+	fmt.Fprintf(stdout, "%s %s/%s: %s %s %s \n", kind, meta.Namespace, meta.Name, plan.Kind, name, planVerbs[plan.Action])
+	if plan.Action == storageapiv1.PlanActionNone {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(plan.Diff, "\n"), "\n") {
+		fmt.Fprintf(stdout, "    %s\n", line)
+	}
+}
+
+// plan plans the clusters, then the pools, then the volumes.
+func (p *planner) plan(ctx context.Context, m *manifests) error {
+	for _, cluster := range m.clusters {
+		clusters := p.clusters(cluster.ObjectMeta.Namespace)
+		plan, err := planOrUpdate(
+			func() (*storageapiv1.Plan, error) { return clusters.PlanCreate(ctx, cluster) },
+			func() (*storageapiv1.Plan, error) { return clusters.PlanUpdate(ctx, cluster) })
+		if err != nil {
+			return fmt.Errorf("Failed to plan storage cluster %s: %w", cluster.ObjectMeta.Name, err)
+		}
+		p.report("storagecluster", cluster.ObjectMeta, plan)
+	}
+	for _, pool := range m.pools {
+		pools := p.pools(pool.ObjectMeta.Namespace)
+		plan, err := planOrUpdate(
+			func() (*storageapiv1.Plan, error) { return pools.PlanCreate(ctx, pool) },
+			func() (*storageapiv1.Plan, error) { return pools.PlanUpdate(ctx, pool) })
+		if err != nil {
+			return fmt.Errorf("Failed to plan storage pool %s: %w", pool.ObjectMeta.Name, err)
+		}
+		p.report("storagepool", pool.ObjectMeta, plan)
+	}
+	for _, volume := range m.volumes {
+		volumes := p.volumes(volume.ObjectMeta.Namespace)
+		plan, err := planOrUpdate(
+			func() (*storageapiv1.Plan, error) { return volumes.PlanCreate(ctx, volume) },
+			func() (*storageapiv1.Plan, error) { return volumes.PlanUpdate(ctx, volume) })
+		if err != nil {
+			return fmt.Errorf("Failed to plan storage volume %s: %w", volume.ObjectMeta.Name, err)
+		}
+		p.report("storagevolume", volume.ObjectMeta, plan)
+	}
+	return nil
+}
+
+func (p *planner) printSummary() {
+	fmt.Fprintf(stdout, "%d to create, %d to update, %d unchanged \n",
+		p.results[storageapiv1.PlanActionCreate],
+		p.results[storageapiv1.PlanActionUpdate]+p.results[storageapiv1.PlanActionReplace],
+		p.results[storageapiv1.PlanActionNone])
+}
+
+func planCommand(args []string) error {
+	var o commonOptions
+	var files fileList
+
+	fs := newFlagSet("plan")
+	o.addFlags(fs)
+	fs.Var(&files, "f", "Manifest file or directory of manifests to plan, \"-\" reads stdin, repeatable")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return usageErrorf("Missing -f")
+	}
+	m, err := loadManifests(files, o.namespace)
+	if err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	p := &planner{
+		clusters: c.StorageClusters,
+		pools:    c.StoragePools,
+		volumes:  c.StorageVolumes,
+	}
+	err = p.plan(context.Background(), m)
+	p.printSummary()
+	return err
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// poolOptions are the flags of the pool subcommands.
+type poolOptions struct {
+	commonOptions
+
+	name            string
+	cluster         string
+	failureDomain   string
+	durabilityClass string
+	durabilityLevel string
+	perfClass       string
+	quota           string
+	quotaObjects    uint64
+	output          string
+
+	wait    bool
+	timeout time.Duration
+}
+
+func (o *poolOptions) addPolicyFlags(fs *flag.FlagSet, defaults bool) {
+	var failureDomain, durabilityClass, durabilityLevel string
+
+	if defaults {
+		failureDomain = string(storageapiv1.FailureDomainHost)
+		durabilityClass = string(storageapiv1.DurabilityClassReplicated)
+		durabilityLevel = string(storageapiv1.DurabilityLevelNormal)
+	}
+	fs.StringVar(&o.failureDomain, "failure-domain", failureDomain, "Failure domain, host|rack")
+	fs.StringVar(&o.durabilityClass, "durability-class", durabilityClass, "Durability class, replicated|erasurecoded")
+	fs.StringVar(&o.durabilityLevel, "durability-level", durabilityLevel, "Durability level, low|semi|normal|high")
+	fs.StringVar(&o.perfClass, "perf-class", "", "Performance class of the devices, standard|medium|fast")
+}
+
+func (o *poolOptions) addSpecFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.quota, "quota", "", "Quota of the pool, in bytes or as a size such as 500Gi")
+	fs.Uint64Var(&o.quotaObjects, "quota-objects", 0, "Maximum number of objects in the pool")
+}
+
+// applySpec sets the spec fields of the flags given on the command line,
+// all policy flags are applied when create is set.
+func (o *poolOptions) applySpec(fs *flag.FlagSet, spec *storageapiv1.StoragePoolSpec, create bool) error {
+	if create || isSet(fs, "failure-domain") {
+		spec.DurabilityPolicy.FailureDomain = storageapiv1.FailureDomain(o.failureDomain)
+	}
+	if create || isSet(fs, "durability-class") {
+		spec.DurabilityPolicy.DurabilityClass = storageapiv1.DurabilityClass(o.durabilityClass)
+	}
+	if create || isSet(fs, "durability-level") {
+		spec.DurabilityPolicy.DurabilityLevel = storageapiv1.DurabilityLevel(o.durabilityLevel)
+	}
+	if create || isSet(fs, "perf-class") {
+		spec.PerfPolicy.IoPerfClass = storageapiv1.DevClass(o.perfClass)
+	}
+	if isSet(fs, "quota") {
+		quota, err := resource.ParseQuantity(o.quota)
+		if err != nil {
+			return usageErrorf("Invalid --quota %q: %v", o.quota, err)
+		}
+		spec.Quota = quota
+	}
+	if isSet(fs, "quota-objects") {
+		spec.QuotaObjects = o.quotaObjects
+	}
+	return nil
+}
+
+func poolCommands() subcommands {
+	return subcommands{
+		"create": createPoolCommand,
+		"get":    getPoolCommand,
+		"list":   listPoolCommand,
+		"update": updatePoolCommand,
+		"delete": deletePoolCommand,
+	}
+}
+
+const poolHeader string = "NAME\tCLUSTER\tFAILURE-DOMAIN\tDURABILITY\tLEVEL\tPERF\tQUOTA\tPHASE"
+
+func poolRow(pool *storageapiv1.StoragePool) []string {
+	quota := ""
+	if !pool.Spec.Quota.IsZero() {
+		quota = pool.Spec.Quota.String()
+	}
+	if pool.Spec.QuotaObjects != 0 {
+		quota += " " + strconv.FormatUint(pool.Spec.QuotaObjects, 10) + " objects"
+	}
+	dpolicy := pool.Spec.DurabilityPolicy
+	return []string{pool.ObjectMeta.Name, pool.Spec.ClusterID, string(dpolicy.FailureDomain),
+		string(dpolicy.DurabilityClass), string(dpolicy.DurabilityLevel), string(pool.Spec.PerfPolicy.IoPerfClass),
+		quota, string(pool.Status.Phase)}
+}
+
+func createPoolCommand(args []string) error {
+	var o poolOptions
+
+	fs := newFlagSet("pool create")
+	o.addFlags(fs)
+	o.addPolicyFlags(fs, true)
+	o.addSpecFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage pool")
+	fs.StringVar(&o.cluster, "cluster", "", "Storage cluster of the pool, defaults to the namespace")
+	fs.BoolVar(&o.wait, "wait", false, "Wait for the storage pool to become ready")
+	fs.DurationVar(&o.timeout, "timeout", 10*time.Minute, "Timeout of --wait")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	if len(o.cluster) == 0 {
+		o.cluster = o.namespace
+	}
+	pool := &storageapiv1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.name,
+			Namespace: o.namespace,
+		},
+		Spec: storageapiv1.StoragePoolSpec{
+			ClusterID: o.cluster,
+		},
+	}
+	if err := o.applySpec(fs, &pool.Spec, true); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	pools := c.StoragePools(o.namespace)
+	err = pools.Create(pool)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage pool %s created \n", o.name)
+	if !o.wait {
+		return nil
+	}
+	_, err = pools.WaitForPoolReady(context.Background(), o.name, storageapiv1.WaitOptions{
+		Timeout: o.timeout,
+		Progress: func(status storageapiv1.WaitStatus) {
+			fmt.Fprintf(stderr, "Waiting for storage pool %s, phase %s \n", o.name, status.Phase)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage pool %s is ready \n", o.name)
+	return nil
+}
+
+func getPoolCommand(args []string) error {
+	var o poolOptions
+
+	fs := newFlagSet("pool get")
+	o.addFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage pool")
+	fs.StringVar(&o.output, "o", "table", "Output format, table|yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	pool, err := c.StoragePools(o.namespace).Get(o.name)
+	if err != nil {
+		return err
+	}
+	return printObject(o.output, pool, poolHeader, poolRow(pool))
+}
+
+func listPoolCommand(args []string) error {
+	var o poolOptions
+	var selector string
+
+	fs := newFlagSet("pool list")
+	o.addFlags(fs)
+	o.addPolicyFlags(fs, false)
+	fs.StringVar(&selector, "l", "", "Label selector of the storage pools")
+	fs.StringVar(&o.output, "o", "table", "Output format, table|yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	filter := &storageapiv1.StoragePoolFilter{
+		FailureDomain:   storageapiv1.FailureDomain(o.failureDomain),
+		DurabilityClass: storageapiv1.DurabilityClass(o.durabilityClass),
+		DurabilityLevel: storageapiv1.DurabilityLevel(o.durabilityLevel),
+		IoPerfClass:     storageapiv1.DevClass(o.perfClass),
+	}
+	pools, err := c.StoragePools(o.namespace).List(metav1.ListOptions{LabelSelector: selector}, filter)
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for i := range pools.Items {
+		rows = append(rows, poolRow(&pools.Items[i]))
+	}
+	return printObject(o.output, pools, poolHeader, rows...)
+}
+
+func updatePoolCommand(args []string) error {
+	var o poolOptions
+
+	fs := newFlagSet("pool update")
+	o.addFlags(fs)
+	o.addPolicyFlags(fs, false)
+	o.addSpecFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage pool")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	pools := c.StoragePools(o.namespace)
+	pool, err := pools.Get(o.name)
+	if err != nil {
+		return err
+	}
+	if err := o.applySpec(fs, &pool.Spec, false); err != nil {
+		return err
+	}
+	err = pools.Update(pool)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage pool %s updated \n", o.name)
+	return nil
+}
+
+func deletePoolCommand(args []string) error {
+	var o poolOptions
+
+	fs := newFlagSet("pool delete")
+	o.addFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage pool")
+	fs.BoolVar(&o.wait, "wait", false, "Wait for the storage pool to be deleted")
+	fs.DurationVar(&o.timeout, "timeout", 10*time.Minute, "Timeout of --wait")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	pools := c.StoragePools(o.namespace)
+	err = pools.Delete(o.name)
+	if err != nil {
+		return err
+	}
+	if o.wait {
+		err = pools.WaitForPoolDeleted(context.Background(), o.name, storageapiv1.WaitOptions{Timeout: o.timeout})
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "Storage pool %s deleted \n", o.name)
+	return nil
+}
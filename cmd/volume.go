@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// volumeOptions are the flags of the volume subcommands.
+type volumeOptions struct {
+	commonOptions
+
+	name           string
+	cluster        string
+	pool           string
+	fstype         string
+	readOnly       bool
+	reclaim        bool
+	allowExpansion bool
+	output         string
+}
+
+func (o *volumeOptions) addSpecFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.fstype, "fstype", "ext4", "Filesystem type of the volumes")
+	fs.BoolVar(&o.readOnly, "read-only", false, "Mount the volumes read only")
+	fs.BoolVar(&o.reclaim, "reclaim", true, "Delete the volumes once released, else retain them")
+	fs.BoolVar(&o.allowExpansion, "allow-expansion", true, "Allow the volumes to be expanded")
+}
+
+// applySpec sets the spec fields of the flags given on the command line,
+// all flags are applied when create is set.
+func (o *volumeOptions) applySpec(fs *flag.FlagSet, spec *storageapiv1.StorageVolumeSpec, create bool) {
+	if create || isSet(fs, "fstype") {
+		spec.FSType = o.fstype
+	}
+	if create || isSet(fs, "read-only") {
+		spec.ReadOnly = o.readOnly
+	}
+	if create || isSet(fs, "reclaim") {
+		reclaim := o.reclaim
+		spec.Reclaim = &reclaim
+	}
+	if create || isSet(fs, "allow-expansion") {
+		allowExpansion := o.allowExpansion
+		spec.AllowExpansion = &allowExpansion
+	}
+}
+
+func volumeCommands() subcommands {
+	return subcommands{
+		"create": createVolumeCommand,
+		"get":    getVolumeCommand,
+		"list":   listVolumeCommand,
+		"update": updateVolumeCommand,
+		"delete": deleteVolumeCommand,
+	}
+}
+
+const volumeHeader string = "NAME\tCLUSTER\tPOOL\tFSTYPE\tREADONLY\tRECLAIM\tSTORAGECLASS\tPHASE"
+
+func volumeRow(volume *storageapiv1.StorageVolume) []string {
+	return []string{volume.ObjectMeta.Name, volume.Spec.ClusterID, volume.Spec.PoolID, volume.Spec.FSType,
+		strconv.FormatBool(volume.Spec.ReadOnly), strconv.FormatBool(volume.Spec.Reclaim == nil || *volume.Spec.Reclaim),
+		storageapiv1.StorageClassName(volume.ObjectMeta.Namespace, volume.ObjectMeta.Name), string(volume.Status.Phase)}
+}
+
+func createVolumeCommand(args []string) error {
+	var o volumeOptions
+
+	fs := newFlagSet("volume create")
+	o.addFlags(fs)
+	o.addSpecFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage volume")
+	fs.StringVar(&o.cluster, "cluster", "", "Storage cluster of the volume, defaults to the namespace")
+	fs.StringVar(&o.pool, "pool", "", "Storage pool the volumes are carved from")
+	fs.StringVar(&o.output, "o", "", "Print the storage class, yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	if len(o.pool) == 0 {
+		return usageErrorf("Missing --pool")
+	}
+	if len(o.output) != 0 && o.output != "yaml" && o.output != "json" {
+		return usageErrorf("Invalid output format %q, one of yaml|json", o.output)
+	}
+	if len(o.cluster) == 0 {
+		o.cluster = o.namespace
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	volume := &storageapiv1.StorageVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.name,
+			Namespace: o.namespace,
+		},
+		Spec: storageapiv1.StorageVolumeSpec{
+			VolumeType: storageapiv1.BlockVolume,
+			ClusterID:  o.cluster,
+			PoolID:     o.pool,
+		},
+	}
+	o.applySpec(fs, &volume.Spec, true)
+	_, class, err := c.StorageVolumes(o.namespace).Create(volume)
+	if err != nil {
+		return err
+	}
+	if len(o.output) != 0 {
+		return printObject(o.output, class, "")
+	}
+	fmt.Fprintf(stdout, "Storage volume %s created, storage class %s \n", o.name, class.ObjectMeta.Name)
+	return nil
+}
+
+func getVolumeCommand(args []string) error {
+	var o volumeOptions
+
+	fs := newFlagSet("volume get")
+	o.addFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage volume")
+	fs.StringVar(&o.output, "o", "table", "Output format, table|yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	volume, err := c.StorageVolumes(o.namespace).Get(o.name)
+	if err != nil {
+		return err
+	}
+	return printObject(o.output, volume, volumeHeader, volumeRow(volume))
+}
+
+func listVolumeCommand(args []string) error {
+	var o volumeOptions
+	var selector string
+
+	fs := newFlagSet("volume list")
+	o.addFlags(fs)
+	fs.StringVar(&selector, "l", "", "Label selector of the storage volumes")
+	fs.StringVar(&o.output, "o", "table", "Output format, table|yaml|json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	volumes, err := c.StorageVolumes(o.namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	var rows [][]string
+	for i := range volumes.Items {
+		rows = append(rows, volumeRow(&volumes.Items[i]))
+	}
+	return printObject(o.output, volumes, volumeHeader, rows...)
+}
+
+func updateVolumeCommand(args []string) error {
+	var o volumeOptions
+
+	fs := newFlagSet("volume update")
+	o.addFlags(fs)
+	o.addSpecFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage volume")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	volumes := c.StorageVolumes(o.namespace)
+	volume, err := volumes.Get(o.name)
+	if err != nil {
+		return err
+	}
+	o.applySpec(fs, &volume.Spec, false)
+	_, err = volumes.Update(volume)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage volume %s updated \n", o.name)
+	return nil
+}
+
+func deleteVolumeCommand(args []string) error {
+	var o volumeOptions
+
+	fs := newFlagSet("volume delete")
+	o.addFlags(fs)
+	fs.StringVar(&o.name, "name", "", "Name of the storage volume")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := requireName(o.name); err != nil {
+		return err
+	}
+	c, err := o.clientset()
+	if err != nil {
+		return err
+	}
+
+	err = c.StorageVolumes(o.namespace).Delete(o.name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Storage volume %s deleted \n", o.name)
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"github.com/murali-bashyam/rookclient/pkg/webhook"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2/klogr"
+)
+
+const (
+	// Address serving the admission webhooks.
+	webhookAddress string = ":8443"
+
+	shutdownTimeout time.Duration = 10 * time.Second
+)
+
+// webhookCommand serves the admission webhooks over TLS with the
+// certificate and key of the flags. The config is only loaded to read a
+// mapping profile ConfigMap.
+func webhookCommand(args []string) error {
+	var address, certFile, keyFile, kubeconfig string
+	var profileOpts profileOptions
+	var config *restclient.Config
+
+	fs := newFlagSet("webhook")
+	fs.StringVar(&address, "address", webhookAddress, "Address to serve the webhooks on")
+	fs.StringVar(&certFile, "tls-cert-file", "", "File of the serving certificate")
+	fs.StringVar(&keyFile, "tls-private-key-file", "", "File of the serving certificate key")
+	fs.StringVar(&kubeconfig, "kubeconfig", "", kubeconfigUsage)
+	profileOpts.addFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if len(certFile) == 0 || len(keyFile) == 0 {
+		return usageErrorf("Missing --tls-cert-file or --tls-private-key-file")
+	}
+	if err := profileOpts.validate(); err != nil {
+		return err
+	}
+	if len(profileOpts.configMap) != 0 {
+		var err error
+		if config, err = loadConfig(kubeconfig); err != nil {
+			return err
+		}
+	}
+	profile, err := profileOpts.load(context.Background(), config)
+	if err != nil {
+		return err
+	}
+	return runWebhook(address, certFile, keyFile, profile)
+}
+
+// runWebhook serves the admission webhooks until the process is terminated,
+// validating the storage pools with profile.
+func runWebhook(address string, certFile string, keyFile string, profile *storageapiv1.MappingProfile) error {
+	server := &http.Server{
+		Addr:    address,
+		Handler: webhook.NewHandler(profile),
+	}
+	serveErrs := make(chan error, 1)
+	go func() {
+		serveErrs <- server.ListenAndServeTLS(certFile, keyFile)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serveErrs:
+		return fmt.Errorf("Failed to serve webhooks: %v", err)
+	case sig := <-signals:
+		klogr.New().Info("Stopping webhook", "signal", sig.String())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
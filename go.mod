@@ -3,35 +3,44 @@ module github.com/murali-bashyam/rookclient
 go 1.15
 
 require (
-	github.com/rook/rook v1.4.6
-	k8s.io/apimachinery v0.17.2
+	github.com/go-logr/logr v0.3.0
+	github.com/prometheus/client_golang v1.8.0
+	github.com/rook/rook v1.5.12
+	k8s.io/api v0.20.0
+	k8s.io/apiextensions-apiserver v0.20.0
+	k8s.io/apimachinery v0.20.0
 	k8s.io/client-go v12.0.0+incompatible
+	k8s.io/klog/v2 v2.4.0
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
-	k8s.io/api => k8s.io/api v0.17.2
-	k8s.io/apiextensions-apiserver => k8s.io/apiextensions-apiserver v0.17.2
-	k8s.io/apimachinery => k8s.io/apimachinery v0.17.2
-	k8s.io/apiserver => k8s.io/apiserver v0.17.2
-	k8s.io/cli-runtime => k8s.io/cli-runtime v0.17.2
-	k8s.io/client-go => k8s.io/client-go v0.17.2
-	k8s.io/cloud-provider => k8s.io/cloud-provider v0.17.2
-	k8s.io/cluster-bootstrap => k8s.io/cluster-bootstrap v0.17.2
-	k8s.io/code-generator => k8s.io/code-generator v0.17.2
-	k8s.io/component-base => k8s.io/component-base v0.17.2
-	k8s.io/cri-api => k8s.io/cri-api v0.17.2
-	k8s.io/csi-translation-lib => k8s.io/csi-translation-lib v0.17.2
-	k8s.io/kube-aggregator => k8s.io/kube-aggregator v0.17.2
-	k8s.io/kube-controller-manager => k8s.io/kube-controller-manager v0.17.2
-	k8s.io/kube-openapi => k8s.io/kube-openapi v0.0.0-20191107075043-30be4d16710a // forces sigs.k8s.io/structured-merge-diff to v1 and not v3
-	k8s.io/kube-proxy => k8s.io/kube-proxy v0.17.2
-	k8s.io/kube-scheduler => k8s.io/kube-scheduler v0.17.2
-	k8s.io/kubectl => k8s.io/kubectl v0.17.2
-	k8s.io/kubelet => k8s.io/kubelet v0.17.2
-	k8s.io/legacy-cloud-providers => k8s.io/legacy-cloud-providers v0.17.2
-	k8s.io/metrics => k8s.io/metrics v0.17.2
-	k8s.io/node-api => k8s.io/node-api v0.17.2
-	k8s.io/sample-apiserver => k8s.io/sample-apiserver v0.17.2
-	k8s.io/sample-cli-plugin => k8s.io/sample-cli-plugin v0.17.2
-	k8s.io/sample-controller => k8s.io/sample-controller v0.17.2
+	k8s.io/api => k8s.io/api v0.20.0
+	k8s.io/apiextensions-apiserver => k8s.io/apiextensions-apiserver v0.20.0
+	k8s.io/apimachinery => k8s.io/apimachinery v0.20.0
+	k8s.io/apiserver => k8s.io/apiserver v0.20.0
+	k8s.io/cli-runtime => k8s.io/cli-runtime v0.20.0
+	k8s.io/client-go => k8s.io/client-go v0.20.0
+	k8s.io/cloud-provider => k8s.io/cloud-provider v0.20.0
+	k8s.io/cluster-bootstrap => k8s.io/cluster-bootstrap v0.20.0
+	k8s.io/code-generator => k8s.io/code-generator v0.20.0
+	k8s.io/component-base => k8s.io/component-base v0.20.0
+	k8s.io/component-helpers => k8s.io/component-helpers v0.20.0
+	k8s.io/controller-manager => k8s.io/controller-manager v0.20.0
+	k8s.io/cri-api => k8s.io/cri-api v0.20.0
+	k8s.io/csi-translation-lib => k8s.io/csi-translation-lib v0.20.0
+	k8s.io/kube-aggregator => k8s.io/kube-aggregator v0.20.0
+	k8s.io/kube-controller-manager => k8s.io/kube-controller-manager v0.20.0
+	k8s.io/kube-openapi => k8s.io/kube-openapi v0.0.0-20201113171705-d219536bb9fd
+	k8s.io/kube-proxy => k8s.io/kube-proxy v0.20.0
+	k8s.io/kube-scheduler => k8s.io/kube-scheduler v0.20.0
+	k8s.io/kubectl => k8s.io/kubectl v0.20.0
+	k8s.io/kubelet => k8s.io/kubelet v0.20.0
+	k8s.io/legacy-cloud-providers => k8s.io/legacy-cloud-providers v0.20.0
+	k8s.io/metrics => k8s.io/metrics v0.20.0
+	k8s.io/mount-utils => k8s.io/mount-utils v0.20.0
+	k8s.io/node-api => k8s.io/node-api v0.20.0
+	k8s.io/sample-apiserver => k8s.io/sample-apiserver v0.20.0
+	k8s.io/sample-cli-plugin => k8s.io/sample-cli-plugin v0.20.0
+	k8s.io/sample-controller => k8s.io/sample-controller v0.20.0
 )
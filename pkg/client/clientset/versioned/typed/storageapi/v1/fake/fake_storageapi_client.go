@@ -0,0 +1,32 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/typed/storageapi/v1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeStorageV1 struct {
+	*testing.Fake
+}
+
+func (c *FakeStorageV1) StorageClusters(namespace string) v1.StorageClusterInterface {
+	return &FakeStorageClusters{c, namespace}
+}
+
+func (c *FakeStorageV1) StoragePools(namespace string) v1.StoragePoolInterface {
+	return &FakeStoragePools{c, namespace}
+}
+
+func (c *FakeStorageV1) StorageVolumes(namespace string) v1.StorageVolumeInterface {
+	return &FakeStorageVolumes{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeStorageV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
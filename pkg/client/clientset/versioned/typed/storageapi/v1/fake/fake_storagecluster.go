@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeStorageClusters implements StorageClusterInterface
+type FakeStorageClusters struct {
+	Fake *FakeStorageV1
+	ns   string
+}
+
+var storageclustersResource = schema.GroupVersionResource{Group: "storage.rookclient.io", Version: "v1", Resource: "storageclusters"}
+
+var storageclustersKind = schema.GroupVersionKind{Group: "storage.rookclient.io", Version: "v1", Kind: "StorageCluster"}
+
+// Get takes name of the storageCluster, and returns the corresponding storageCluster object, and an error if there is any.
+func (c *FakeStorageClusters) Get(ctx context.Context, name string, options v1.GetOptions) (result *storageapiv1.StorageCluster, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(storageclustersResource, c.ns, name), &storageapiv1.StorageCluster{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageCluster), err
+}
+
+// List takes label and field selectors, and returns the list of StorageClusters that match those selectors.
+func (c *FakeStorageClusters) List(ctx context.Context, opts v1.ListOptions) (result *storageapiv1.StorageClusterList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(storageclustersResource, storageclustersKind, c.ns, opts), &storageapiv1.StorageClusterList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &storageapiv1.StorageClusterList{ListMeta: obj.(*storageapiv1.StorageClusterList).ListMeta}
+	for _, item := range obj.(*storageapiv1.StorageClusterList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested storageClusters.
+func (c *FakeStorageClusters) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(storageclustersResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a storageCluster and creates it.  Returns the server's representation of the storageCluster, and an error, if there is any.
+func (c *FakeStorageClusters) Create(ctx context.Context, storageCluster *storageapiv1.StorageCluster, opts v1.CreateOptions) (result *storageapiv1.StorageCluster, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(storageclustersResource, c.ns, storageCluster), &storageapiv1.StorageCluster{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageCluster), err
+}
+
+// Update takes the representation of a storageCluster and updates it. Returns the server's representation of the storageCluster, and an error, if there is any.
+func (c *FakeStorageClusters) Update(ctx context.Context, storageCluster *storageapiv1.StorageCluster, opts v1.UpdateOptions) (result *storageapiv1.StorageCluster, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(storageclustersResource, c.ns, storageCluster), &storageapiv1.StorageCluster{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageCluster), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeStorageClusters) UpdateStatus(ctx context.Context, storageCluster *storageapiv1.StorageCluster, opts v1.UpdateOptions) (*storageapiv1.StorageCluster, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(storageclustersResource, "status", c.ns, storageCluster), &storageapiv1.StorageCluster{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageCluster), err
+}
+
+// Delete takes name of the storageCluster and deletes it. Returns an error if one occurs.
+func (c *FakeStorageClusters) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(storageclustersResource, c.ns, name), &storageapiv1.StorageCluster{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeStorageClusters) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(storageclustersResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &storageapiv1.StorageClusterList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched storageCluster.
+func (c *FakeStorageClusters) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *storageapiv1.StorageCluster, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(storageclustersResource, c.ns, name, pt, data, subresources...), &storageapiv1.StorageCluster{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageCluster), err
+}
@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeStoragePools implements StoragePoolInterface
+type FakeStoragePools struct {
+	Fake *FakeStorageV1
+	ns   string
+}
+
+var storagepoolsResource = schema.GroupVersionResource{Group: "storage.rookclient.io", Version: "v1", Resource: "storagepools"}
+
+var storagepoolsKind = schema.GroupVersionKind{Group: "storage.rookclient.io", Version: "v1", Kind: "StoragePool"}
+
+// Get takes name of the storagePool, and returns the corresponding storagePool object, and an error if there is any.
+func (c *FakeStoragePools) Get(ctx context.Context, name string, options v1.GetOptions) (result *storageapiv1.StoragePool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(storagepoolsResource, c.ns, name), &storageapiv1.StoragePool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StoragePool), err
+}
+
+// List takes label and field selectors, and returns the list of StoragePools that match those selectors.
+func (c *FakeStoragePools) List(ctx context.Context, opts v1.ListOptions) (result *storageapiv1.StoragePoolList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(storagepoolsResource, storagepoolsKind, c.ns, opts), &storageapiv1.StoragePoolList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &storageapiv1.StoragePoolList{ListMeta: obj.(*storageapiv1.StoragePoolList).ListMeta}
+	for _, item := range obj.(*storageapiv1.StoragePoolList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested storagePools.
+func (c *FakeStoragePools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(storagepoolsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a storagePool and creates it.  Returns the server's representation of the storagePool, and an error, if there is any.
+func (c *FakeStoragePools) Create(ctx context.Context, storagePool *storageapiv1.StoragePool, opts v1.CreateOptions) (result *storageapiv1.StoragePool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(storagepoolsResource, c.ns, storagePool), &storageapiv1.StoragePool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StoragePool), err
+}
+
+// Update takes the representation of a storagePool and updates it. Returns the server's representation of the storagePool, and an error, if there is any.
+func (c *FakeStoragePools) Update(ctx context.Context, storagePool *storageapiv1.StoragePool, opts v1.UpdateOptions) (result *storageapiv1.StoragePool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(storagepoolsResource, c.ns, storagePool), &storageapiv1.StoragePool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StoragePool), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeStoragePools) UpdateStatus(ctx context.Context, storagePool *storageapiv1.StoragePool, opts v1.UpdateOptions) (*storageapiv1.StoragePool, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(storagepoolsResource, "status", c.ns, storagePool), &storageapiv1.StoragePool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StoragePool), err
+}
+
+// Delete takes name of the storagePool and deletes it. Returns an error if one occurs.
+func (c *FakeStoragePools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(storagepoolsResource, c.ns, name), &storageapiv1.StoragePool{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeStoragePools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(storagepoolsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &storageapiv1.StoragePoolList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched storagePool.
+func (c *FakeStoragePools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *storageapiv1.StoragePool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(storagepoolsResource, c.ns, name, pt, data, subresources...), &storageapiv1.StoragePool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StoragePool), err
+}
@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeStorageVolumes implements StorageVolumeInterface
+type FakeStorageVolumes struct {
+	Fake *FakeStorageV1
+	ns   string
+}
+
+var storagevolumesResource = schema.GroupVersionResource{Group: "storage.rookclient.io", Version: "v1", Resource: "storagevolumes"}
+
+var storagevolumesKind = schema.GroupVersionKind{Group: "storage.rookclient.io", Version: "v1", Kind: "StorageVolume"}
+
+// Get takes name of the storageVolume, and returns the corresponding storageVolume object, and an error if there is any.
+func (c *FakeStorageVolumes) Get(ctx context.Context, name string, options v1.GetOptions) (result *storageapiv1.StorageVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(storagevolumesResource, c.ns, name), &storageapiv1.StorageVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageVolume), err
+}
+
+// List takes label and field selectors, and returns the list of StorageVolumes that match those selectors.
+func (c *FakeStorageVolumes) List(ctx context.Context, opts v1.ListOptions) (result *storageapiv1.StorageVolumeList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(storagevolumesResource, storagevolumesKind, c.ns, opts), &storageapiv1.StorageVolumeList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &storageapiv1.StorageVolumeList{ListMeta: obj.(*storageapiv1.StorageVolumeList).ListMeta}
+	for _, item := range obj.(*storageapiv1.StorageVolumeList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested storageVolumes.
+func (c *FakeStorageVolumes) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(storagevolumesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a storageVolume and creates it.  Returns the server's representation of the storageVolume, and an error, if there is any.
+func (c *FakeStorageVolumes) Create(ctx context.Context, storageVolume *storageapiv1.StorageVolume, opts v1.CreateOptions) (result *storageapiv1.StorageVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(storagevolumesResource, c.ns, storageVolume), &storageapiv1.StorageVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageVolume), err
+}
+
+// Update takes the representation of a storageVolume and updates it. Returns the server's representation of the storageVolume, and an error, if there is any.
+func (c *FakeStorageVolumes) Update(ctx context.Context, storageVolume *storageapiv1.StorageVolume, opts v1.UpdateOptions) (result *storageapiv1.StorageVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(storagevolumesResource, c.ns, storageVolume), &storageapiv1.StorageVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageVolume), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeStorageVolumes) UpdateStatus(ctx context.Context, storageVolume *storageapiv1.StorageVolume, opts v1.UpdateOptions) (*storageapiv1.StorageVolume, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(storagevolumesResource, "status", c.ns, storageVolume), &storageapiv1.StorageVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageVolume), err
+}
+
+// Delete takes name of the storageVolume and deletes it. Returns an error if one occurs.
+func (c *FakeStorageVolumes) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(storagevolumesResource, c.ns, name), &storageapiv1.StorageVolume{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeStorageVolumes) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(storagevolumesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &storageapiv1.StorageVolumeList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched storageVolume.
+func (c *FakeStorageVolumes) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *storageapiv1.StorageVolume, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(storagevolumesResource, c.ns, name, pt, data, subresources...), &storageapiv1.StorageVolume{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*storageapiv1.StorageVolume), err
+}
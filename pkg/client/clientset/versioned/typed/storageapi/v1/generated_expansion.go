@@ -0,0 +1,9 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+type StorageClusterExpansion interface{}
+
+type StoragePoolExpansion interface{}
+
+type StorageVolumeExpansion interface{}
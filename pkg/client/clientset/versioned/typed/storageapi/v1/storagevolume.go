@@ -0,0 +1,179 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	scheme "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/scheme"
+	v1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// StorageVolumesGetter has a method to return a StorageVolumeInterface.
+// A group's client should implement this interface.
+type StorageVolumesGetter interface {
+	StorageVolumes(namespace string) StorageVolumeInterface
+}
+
+// StorageVolumeInterface has methods to work with StorageVolume resources.
+type StorageVolumeInterface interface {
+	Create(ctx context.Context, storageVolume *v1.StorageVolume, opts metav1.CreateOptions) (*v1.StorageVolume, error)
+	Update(ctx context.Context, storageVolume *v1.StorageVolume, opts metav1.UpdateOptions) (*v1.StorageVolume, error)
+	UpdateStatus(ctx context.Context, storageVolume *v1.StorageVolume, opts metav1.UpdateOptions) (*v1.StorageVolume, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.StorageVolume, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.StorageVolumeList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.StorageVolume, err error)
+	StorageVolumeExpansion
+}
+
+// storageVolumes implements StorageVolumeInterface
+type storageVolumes struct {
+	client rest.Interface
+	ns     string
+}
+
+// newStorageVolumes returns a StorageVolumes
+func newStorageVolumes(c *StorageV1Client, namespace string) *storageVolumes {
+	return &storageVolumes{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the storageVolume, and returns the corresponding storageVolume object, and an error if there is any.
+func (c *storageVolumes) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.StorageVolume, err error) {
+	result = &v1.StorageVolume{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of StorageVolumes that match those selectors.
+func (c *storageVolumes) List(ctx context.Context, opts metav1.ListOptions) (result *v1.StorageVolumeList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.StorageVolumeList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested storageVolumes.
+func (c *storageVolumes) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a storageVolume and creates it.  Returns the server's representation of the storageVolume, and an error, if there is any.
+func (c *storageVolumes) Create(ctx context.Context, storageVolume *v1.StorageVolume, opts metav1.CreateOptions) (result *v1.StorageVolume, err error) {
+	result = &v1.StorageVolume{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(storageVolume).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a storageVolume and updates it. Returns the server's representation of the storageVolume, and an error, if there is any.
+func (c *storageVolumes) Update(ctx context.Context, storageVolume *v1.StorageVolume, opts metav1.UpdateOptions) (result *v1.StorageVolume, err error) {
+	result = &v1.StorageVolume{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		Name(storageVolume.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(storageVolume).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *storageVolumes) UpdateStatus(ctx context.Context, storageVolume *v1.StorageVolume, opts metav1.UpdateOptions) (result *v1.StorageVolume, err error) {
+	result = &v1.StorageVolume{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		Name(storageVolume.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(storageVolume).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the storageVolume and deletes it. Returns an error if one occurs.
+func (c *storageVolumes) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *storageVolumes) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched storageVolume.
+func (c *storageVolumes) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.StorageVolume, err error) {
+	result = &v1.StorageVolume{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("storagevolumes").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,43 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	internalinterfaces "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// StorageClusters returns a StorageClusterInformer.
+	StorageClusters() StorageClusterInformer
+	// StoragePools returns a StoragePoolInformer.
+	StoragePools() StoragePoolInformer
+	// StorageVolumes returns a StorageVolumeInformer.
+	StorageVolumes() StorageVolumeInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// StorageClusters returns a StorageClusterInformer.
+func (v *version) StorageClusters() StorageClusterInformer {
+	return &storageClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// StoragePools returns a StoragePoolInformer.
+func (v *version) StoragePools() StoragePoolInformer {
+	return &storagePoolInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// StorageVolumes returns a StorageVolumeInformer.
+func (v *version) StorageVolumes() StorageVolumeInformer {
+	return &storageVolumeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
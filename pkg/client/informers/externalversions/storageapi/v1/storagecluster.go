@@ -0,0 +1,74 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	versioned "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/murali-bashyam/rookclient/pkg/client/listers/storageapi/v1"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// StorageClusterInformer provides access to a shared informer and lister for
+// StorageClusters.
+type StorageClusterInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.StorageClusterLister
+}
+
+type storageClusterInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewStorageClusterInformer constructs a new informer for StorageCluster type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewStorageClusterInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredStorageClusterInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredStorageClusterInformer constructs a new informer for StorageCluster type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredStorageClusterInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.StorageV1().StorageClusters(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.StorageV1().StorageClusters(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&storageapiv1.StorageCluster{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *storageClusterInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredStorageClusterInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *storageClusterInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&storageapiv1.StorageCluster{}, f.defaultInformer)
+}
+
+func (f *storageClusterInformer) Lister() v1.StorageClusterLister {
+	return v1.NewStorageClusterLister(f.Informer().GetIndexer())
+}
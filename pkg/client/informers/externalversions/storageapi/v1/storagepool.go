@@ -0,0 +1,74 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	versioned "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions/internalinterfaces"
+	v1 "github.com/murali-bashyam/rookclient/pkg/client/listers/storageapi/v1"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// StoragePoolInformer provides access to a shared informer and lister for
+// StoragePools.
+type StoragePoolInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.StoragePoolLister
+}
+
+type storagePoolInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewStoragePoolInformer constructs a new informer for StoragePool type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewStoragePoolInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredStoragePoolInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredStoragePoolInformer constructs a new informer for StoragePool type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredStoragePoolInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.StorageV1().StoragePools(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.StorageV1().StoragePools(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&storageapiv1.StoragePool{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *storagePoolInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredStoragePoolInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *storagePoolInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&storageapiv1.StoragePool{}, f.defaultInformer)
+}
+
+func (f *storagePoolInformer) Lister() v1.StoragePoolLister {
+	return v1.NewStoragePoolLister(f.Informer().GetIndexer())
+}
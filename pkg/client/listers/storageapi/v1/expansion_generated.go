@@ -0,0 +1,27 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+// StorageClusterListerExpansion allows custom methods to be added to
+// StorageClusterLister.
+type StorageClusterListerExpansion interface{}
+
+// StorageClusterNamespaceListerExpansion allows custom methods to be added to
+// StorageClusterNamespaceLister.
+type StorageClusterNamespaceListerExpansion interface{}
+
+// StoragePoolListerExpansion allows custom methods to be added to
+// StoragePoolLister.
+type StoragePoolListerExpansion interface{}
+
+// StoragePoolNamespaceListerExpansion allows custom methods to be added to
+// StoragePoolNamespaceLister.
+type StoragePoolNamespaceListerExpansion interface{}
+
+// StorageVolumeListerExpansion allows custom methods to be added to
+// StorageVolumeLister.
+type StorageVolumeListerExpansion interface{}
+
+// StorageVolumeNamespaceListerExpansion allows custom methods to be added to
+// StorageVolumeNamespaceLister.
+type StorageVolumeNamespaceListerExpansion interface{}
@@ -0,0 +1,83 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StorageVolumeLister helps list StorageVolumes.
+// All objects returned here must be treated as read-only.
+type StorageVolumeLister interface {
+	// List lists all StorageVolumes in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.StorageVolume, err error)
+	// StorageVolumes returns an object that can list and get StorageVolumes.
+	StorageVolumes(namespace string) StorageVolumeNamespaceLister
+	StorageVolumeListerExpansion
+}
+
+// storageVolumeLister implements the StorageVolumeLister interface.
+type storageVolumeLister struct {
+	indexer cache.Indexer
+}
+
+// NewStorageVolumeLister returns a new StorageVolumeLister.
+func NewStorageVolumeLister(indexer cache.Indexer) StorageVolumeLister {
+	return &storageVolumeLister{indexer: indexer}
+}
+
+// List lists all StorageVolumes in the indexer.
+func (s *storageVolumeLister) List(selector labels.Selector) (ret []*v1.StorageVolume, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.StorageVolume))
+	})
+	return ret, err
+}
+
+// StorageVolumes returns an object that can list and get StorageVolumes.
+func (s *storageVolumeLister) StorageVolumes(namespace string) StorageVolumeNamespaceLister {
+	return storageVolumeNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// StorageVolumeNamespaceLister helps list and get StorageVolumes.
+// All objects returned here must be treated as read-only.
+type StorageVolumeNamespaceLister interface {
+	// List lists all StorageVolumes in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.StorageVolume, err error)
+	// Get retrieves the StorageVolume from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1.StorageVolume, error)
+	StorageVolumeNamespaceListerExpansion
+}
+
+// storageVolumeNamespaceLister implements the StorageVolumeNamespaceLister
+// interface.
+type storageVolumeNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all StorageVolumes in the indexer for a given namespace.
+func (s storageVolumeNamespaceLister) List(selector labels.Selector) (ret []*v1.StorageVolume, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.StorageVolume))
+	})
+	return ret, err
+}
+
+// Get retrieves the StorageVolume from the indexer for a given namespace and name.
+func (s storageVolumeNamespaceLister) Get(name string) (*v1.StorageVolume, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("storagevolume"), name)
+	}
+	return obj.(*v1.StorageVolume), nil
+}
@@ -1,34 +1,16 @@
+// Package storageapi is kept for the importers of the original clientset
+// location, it forwards to github.com/murali-bashyam/rookclient/pkg/storageapi.
 package storageapi
 
 import (
-	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	"github.com/murali-bashyam/rookclient/pkg/storageapi"
 	restclient "k8s.io/client-go/rest"
 )
 
-type Clientset struct {
-	rookclnt *rookclient.Clientset
-}
+// Clientset is the clientset of pkg/storageapi.
+type Clientset = storageapi.Clientset
 
+// NewForConfig is pkg/storageapi.NewForConfig.
 func NewForConfig(c *restclient.Config) (*Clientset, error) {
-	var cs Clientset
-
-	rookclnt, err := rookclient.NewForConfig(config)
-	if err != nil {
-		log.Println("Failed to initialize Rook Ceph clientset", err)
-		return nil, err
-	}
-	cs.rookclnt = rookclnt
-	return &cs, nil
-}
-
-func (c *Clientset) StorageClusters(ns string) *StorageClusters {
-	return newStorageClusters(c.rookclnt, ns)
-}
-
-func (c *Clientset) StoragePools(ns string) *StoragePools {
-	return newStoragePools(c.rookclnt, ns)
-}
-
-func (c *Clientset) StorageVolumes(ns string) *StorageVolumes {
-	return newStorageVolumes(c.rookclnt, ns)
+	return storageapi.NewForConfig(c)
 }
@@ -0,0 +1,157 @@
+// Package controller reconciles the storage.rookclient.io objects into
+// their rook Ceph and Kubernetes counterparts.
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// reconciler reconciles the object namespace/name.
+type reconciler func(ctx context.Context, namespace, name string) error
+
+// controller queues the keys of changed objects and reconciles them, a
+// failed key is requeued with backoff.
+type controller struct {
+	name      string
+	queue     workqueue.RateLimitingInterface
+	synced    []cache.InformerSynced
+	reconcile reconciler
+	log       logr.Logger
+}
+
+// newController returns a controller logging to log, a nil log discards
+// the messages.
+func newController(name string, reconcile reconciler, log logr.Logger) controller {
+	if log == nil {
+		log = logr.Discard()
+	}
+	return controller{
+		name:      name,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		reconcile: reconcile,
+		log:       log.WithValues("controller", name),
+	}
+}
+
+// enqueue queues the key of obj, tombstones of deleted objects included.
+func (c *controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *controller) enqueueKey(namespace, name string) {
+	c.queue.Add(namespace + "/" + name)
+}
+
+func (c *controller) eventHandler() cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueue(newObj)
+		},
+		DeleteFunc: c.enqueue,
+	}
+}
+
+// Run waits for the informer caches and reconciles the queued keys with
+// workers goroutines until ctx is done.
+func (c *controller) Run(ctx context.Context, workers int) error {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	c.log.Info("Starting controller")
+	if !cache.WaitForCacheSync(ctx.Done(), c.synced...) {
+		return fmt.Errorf("Failed to sync caches of %s controller", c.name)
+	}
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+	}
+	<-ctx.Done()
+	c.log.Info("Stopping controller")
+	return nil
+}
+
+// HasSynced reports whether the informer caches of the controller are synced.
+func (c *controller) HasSynced() bool {
+	for _, synced := range c.synced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *controller) runWorker(ctx context.Context) {
+	for c.processNextItem(ctx) {
+	}
+}
+
+func (c *controller) processNextItem(ctx context.Context) bool {
+	item, shutdown := c.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.queue.Done(item)
+
+	key := item.(string)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(err)
+		c.queue.Forget(item)
+		return true
+	}
+	start := time.Now()
+	err = c.reconcile(ctx, namespace, name)
+	observeReconcile(c.name, start, err)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Failed to reconcile %s %s: %v", c.name, key, err))
+		if !isPermanent(err) {
+			c.queue.AddRateLimited(item)
+			return true
+		}
+	}
+	c.queue.Forget(item)
+	return true
+}
+
+// isPermanent reports whether err is a spec or ownership error, retrying
+// cannot succeed until the object or the object it maps to changes and is
+// queued again.
+func isPermanent(err error) bool {
+	return errors.Is(err, storageapiv1.ErrInvalidPolicy) || errors.Is(err, storageapiv1.ErrUnsupportedTransition) ||
+		errors.Is(err, storageapiv1.ErrOwnerConflict)
+}
+
+func hasFinalizer(finalizers []string, finalizer string) bool {
+	for _, f := range finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(finalizers []string, finalizer string) []string {
+	var result []string
+
+	for _, f := range finalizers {
+		if f != finalizer {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+)
+
+func TestProcessNextItemRequeue(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		requeue bool
+	}{
+		{name: "success", err: nil},
+		{name: "transient error", err: errors.New("connection refused"), requeue: true},
+		{name: "invalid policy", err: &storageapiv1.InvalidPolicyError{Field: "spec.durabilitypolicy.failuredomain", Value: "zone"}},
+		{name: "unsupported transition", err: &storageapiv1.UnsupportedTransitionError{Kind: "Ceph block pool", Name: "bpool1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newController("requeue-test", func(ctx context.Context, namespace, name string) error {
+				return tt.err
+			}, nil)
+			defer c.queue.ShutDown()
+
+			c.enqueueKey("ns", "obj")
+			c.processNextItem(context.TODO())
+			if requeued := c.queue.NumRequeues("ns/obj") != 0; requeued != tt.requeue {
+				t.Errorf("expected requeue %v, got %v", tt.requeue, requeued)
+			}
+		})
+	}
+}
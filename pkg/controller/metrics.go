@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"time"
+
+	storagelisters "github.com/murali-bashyam/rookclient/pkg/client/listers/storageapi/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const metricsNamespace string = "rookclient"
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_total",
+		Help:      "Number of reconciles per controller.",
+	}, []string{"controller"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of failed reconciles per controller.",
+	}, []string{"controller"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciles per controller.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"controller"})
+
+	storageClustersDesc = prometheus.NewDesc(metricsNamespace+"_storage_clusters",
+		"Number of storage clusters per phase.", []string{"phase"}, nil)
+
+	storagePoolsDesc = prometheus.NewDesc(metricsNamespace+"_storage_pools",
+		"Number of storage pools per phase.", []string{"phase"}, nil)
+)
+
+// observeReconcile records a reconcile of the controller started at start.
+func observeReconcile(controller string, start time.Time, err error) {
+	reconcileTotal.WithLabelValues(controller).Inc()
+	reconcileDuration.WithLabelValues(controller).Observe(time.Since(start).Seconds())
+	if err != nil {
+		reconcileErrors.WithLabelValues(controller).Inc()
+	}
+}
+
+// phaseCollector counts the cached storage clusters and pools per phase
+// when scraped.
+type phaseCollector struct {
+	clusters storagelisters.StorageClusterLister
+	pools    storagelisters.StoragePoolLister
+}
+
+func (p *phaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storageClustersDesc
+	ch <- storagePoolsDesc
+}
+
+func (p *phaseCollector) Collect(ch chan<- prometheus.Metric) {
+	clusterPhases := map[string]int{}
+	poolPhases := map[string]int{}
+
+	clusters, err := p.clusters.List(labels.Everything())
+	if err == nil {
+		for _, cluster := range clusters {
+			clusterPhases[string(cluster.Status.Phase)]++
+		}
+	}
+	blockpools, err := p.pools.List(labels.Everything())
+	if err == nil {
+		for _, blockpool := range blockpools {
+			poolPhases[string(blockpool.Status.Phase)]++
+		}
+	}
+	for phase, count := range clusterPhases {
+		ch <- prometheus.MustNewConstMetric(storageClustersDesc, prometheus.GaugeValue, float64(count), phase)
+	}
+	for phase, count := range poolPhases {
+		ch <- prometheus.MustNewConstMetric(storagePoolsDesc, prometheus.GaugeValue, float64(count), phase)
+	}
+}
+
+// RegisterMetrics registers the reconcile metrics of the controllers and
+// the number of storage clusters and pools per phase in registerer.
+func RegisterMetrics(registerer prometheus.Registerer, clusters storagelisters.StorageClusterLister,
+	pools storagelisters.StoragePoolLister) error {
+	collectors := []prometheus.Collector{
+		reconcileTotal,
+		reconcileErrors,
+		reconcileDuration,
+		&phaseCollector{clusters: clusters, pools: pools},
+	}
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	storagefake "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/fake"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileMetrics(t *testing.T) {
+	var result error
+	c := newController("metrics-test", func(ctx context.Context, namespace, name string) error {
+		return result
+	}, nil)
+	defer c.queue.ShutDown()
+
+	c.enqueueKey("ns", "ok")
+	c.processNextItem(context.TODO())
+	result = errors.New("failed")
+	c.enqueueKey("ns", "failed")
+	c.processNextItem(context.TODO())
+
+	if total := testutil.ToFloat64(reconcileTotal.WithLabelValues("metrics-test")); total != 2 {
+		t.Errorf("expected 2 reconciles, got %v", total)
+	}
+	if failed := testutil.ToFloat64(reconcileErrors.WithLabelValues("metrics-test")); failed != 1 {
+		t.Errorf("expected 1 failed reconcile, got %v", failed)
+	}
+}
+
+func TestPhaseMetrics(t *testing.T) {
+	informers := storageinformers.NewSharedInformerFactory(storagefake.NewSimpleClientset(), 0)
+	clusterInformer := informers.Storage().V1().StorageClusters()
+	poolInformer := informers.Storage().V1().StoragePools()
+	for _, name := range []string{"c1", "c2"} {
+		clusterInformer.Informer().GetIndexer().Add(&storageapiv1.StorageCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "rook-ceph"},
+			Status:     storageapiv1.StorageClusterStatus{Phase: storageapiv1.ClusterPhaseReady},
+		})
+	}
+	poolInformer.Informer().GetIndexer().Add(&storageapiv1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "bpool1", Namespace: "apps"},
+		Status:     storageapiv1.StoragePoolStatus{Phase: storageapiv1.PoolPhaseFailure},
+	})
+
+	registry := prometheus.NewRegistry()
+	if err := RegisterMetrics(registry, clusterInformer.Lister(), poolInformer.Lister()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `
+# HELP rookclient_storage_clusters Number of storage clusters per phase.
+# TYPE rookclient_storage_clusters gauge
+rookclient_storage_clusters{phase="Ready"} 2
+# HELP rookclient_storage_pools Number of storage pools per phase.
+# TYPE rookclient_storage_pools gauge
+rookclient_storage_pools{phase="Failure"} 1
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"rookclient_storage_clusters", "rookclient_storage_pools")
+	if err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+}
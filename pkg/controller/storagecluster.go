@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	storageclient "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions/storageapi/v1"
+	storagelisters "github.com/murali-bashyam/rookclient/pkg/client/listers/storageapi/v1"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Finalizer keeping a storage cluster until its Ceph cluster is deleted.
+const storageClusterFinalizer string = "storage.rookclient.io/storagecluster"
+
+// StorageClusterController reconciles StorageCluster objects into Ceph
+// clusters of the same name and namespace.
+type StorageClusterController struct {
+	controller
+
+	storageclnt storageclient.Interface
+	rookclnt    rookclient.Interface
+	kubeclnt    kubernetes.Interface
+	lister      storagelisters.StorageClusterLister
+}
+
+// NewStorageClusterController returns a controller watching the storage
+// clusters of clusterInformer and the Ceph clusters of cephInformer, whose
+// changes are reflected in the status of the storage cluster. A nil log
+// discards the messages of the controller.
+func NewStorageClusterController(storageclnt storageclient.Interface, rookclnt rookclient.Interface,
+	kubeclnt kubernetes.Interface, clusterInformer storageinformers.StorageClusterInformer,
+	cephInformer *storageapiv1.StorageClusterInformer, log logr.Logger) *StorageClusterController {
+	c := &StorageClusterController{
+		storageclnt: storageclnt,
+		rookclnt:    rookclnt,
+		kubeclnt:    kubeclnt,
+		lister:      clusterInformer.Lister(),
+	}
+	c.controller = newController("storagecluster", c.reconcileCluster, log)
+	c.synced = append(c.synced, clusterInformer.Informer().HasSynced, cephInformer.Informer().HasSynced)
+
+	clusterInformer.Informer().AddEventHandler(c.eventHandler())
+	enqueueCephCluster := func(cluster *storageapiv1.StorageCluster) {
+		c.enqueueKey(cluster.ObjectMeta.Namespace, cluster.ObjectMeta.Name)
+	}
+	cephInformer.AddEventHandler(storageapiv1.StorageClusterEventHandlerFuncs{
+		AddFunc: enqueueCephCluster,
+		UpdateFunc: func(oldCluster, newCluster *storageapiv1.StorageCluster) {
+			enqueueCephCluster(newCluster)
+		},
+		DeleteFunc: enqueueCephCluster,
+	})
+	return c
+}
+
+func (c *StorageClusterController) reconcileCluster(ctx context.Context, namespace, name string) error {
+	cluster, err := c.lister.StorageClusters(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	cluster = cluster.DeepCopy()
+	clusters := &storageapiv1.StorageClusters{
+		Namespace:  namespace,
+		Client:     c.rookclnt,
+		KubeClient: c.kubeclnt,
+		Log:        c.log,
+	}
+
+	if cluster.ObjectMeta.DeletionTimestamp != nil {
+		return c.finalizeCluster(ctx, clusters, cluster)
+	}
+	if !hasFinalizer(cluster.ObjectMeta.Finalizers, storageClusterFinalizer) {
+		cluster.ObjectMeta.Finalizers = append(cluster.ObjectMeta.Finalizers, storageClusterFinalizer)
+		cluster, err = c.storageclnt.StorageV1().StorageClusters(namespace).Update(ctx, cluster, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = c.rookclnt.CephV1().CephClusters(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = clusters.CreateContext(ctx, cluster.DeepCopy())
+	} else if err == nil {
+		_, err = clusters.UpdateContext(ctx, cluster.DeepCopy())
+	}
+	if err != nil {
+		status := storageapiv1.StorageClusterStatus{
+			Phase:   storageapiv1.ClusterPhaseFailure,
+			State:   storageapiv1.ClusterStateError,
+			Message: err.Error(),
+		}
+		if statusErr := c.updateStatus(ctx, cluster, status); statusErr != nil {
+			c.log.Error(statusErr, "Failed to update status of storage cluster", "namespace", namespace, "name", name)
+		}
+		return err
+	}
+
+	err = c.setOwnerReference(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	translated, err := clusters.GetContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	return c.updateStatus(ctx, cluster, translated.Status)
+}
+
+// finalizeCluster deletes the Ceph cluster and releases the storage cluster.
+func (c *StorageClusterController) finalizeCluster(ctx context.Context, clusters *storageapiv1.StorageClusters,
+	cluster *storageapiv1.StorageCluster) error {
+	if !hasFinalizer(cluster.ObjectMeta.Finalizers, storageClusterFinalizer) {
+		return nil
+	}
+	err := clusters.DeleteContext(ctx, cluster.ObjectMeta.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	cluster.ObjectMeta.Finalizers = removeFinalizer(cluster.ObjectMeta.Finalizers, storageClusterFinalizer)
+	_, err = c.storageclnt.StorageV1().StorageClusters(cluster.ObjectMeta.Namespace).Update(ctx, cluster, metav1.UpdateOptions{})
+	return err
+}
+
+// setOwnerReference makes the storage cluster the controller of its Ceph
+// cluster, unless the Ceph cluster already has a controller.
+func (c *StorageClusterController) setOwnerReference(ctx context.Context, cluster *storageapiv1.StorageCluster) error {
+	cephclusters := c.rookclnt.CephV1().CephClusters(cluster.ObjectMeta.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cephcluster, err := cephclusters.Get(ctx, cluster.ObjectMeta.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if metav1.GetControllerOf(cephcluster) != nil {
+			return nil
+		}
+		ownerRef := metav1.NewControllerRef(cluster, storageapiv1.SchemeGroupVersion.WithKind("StorageCluster"))
+		cephcluster.ObjectMeta.OwnerReferences = append(cephcluster.ObjectMeta.OwnerReferences, *ownerRef)
+		_, err = cephclusters.Update(ctx, cephcluster, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func (c *StorageClusterController) updateStatus(ctx context.Context, cluster *storageapiv1.StorageCluster,
+	status storageapiv1.StorageClusterStatus) error {
+	if cluster.Status == status {
+		return nil
+	}
+	cluster.Status = status
+	_, err := c.storageclnt.StorageV1().StorageClusters(cluster.ObjectMeta.Namespace).UpdateStatus(ctx, cluster, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	storagefake "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/fake"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+type clusterFixture struct {
+	storageclnt *storagefake.Clientset
+	rookclnt    *rookfake.Clientset
+	controller  *StorageClusterController
+}
+
+// newClusterFixture returns a controller whose cache holds cluster, the
+// cache is not updated by the reconciles.
+func newClusterFixture(cluster *storageapiv1.StorageCluster) *clusterFixture {
+	f := &clusterFixture{
+		storageclnt: storagefake.NewSimpleClientset(cluster),
+		rookclnt:    rookfake.NewSimpleClientset(),
+	}
+	informers := storageinformers.NewSharedInformerFactory(f.storageclnt, 0)
+	clusterInformer := informers.Storage().V1().StorageClusters()
+	cephInformer := storageapiv1.NewSharedInformerFactory(f.rookclnt, "", 0).StorageClusters()
+	f.controller = NewStorageClusterController(f.storageclnt, f.rookclnt, kubefake.NewSimpleClientset(),
+		clusterInformer, cephInformer, nil)
+	clusterInformer.Informer().GetIndexer().Add(cluster)
+	return f
+}
+
+func (f *clusterFixture) getCluster(t *testing.T) *storageapiv1.StorageCluster {
+	cluster, err := f.storageclnt.StorageV1().StorageClusters("rook-ceph").Get(context.TODO(), "c1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cluster
+}
+
+func newStorageCluster() *storageapiv1.StorageCluster {
+	return &storageapiv1.StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "c1",
+			Namespace: "rook-ceph",
+			UID:       types.UID("c1-uid"),
+		},
+		Spec: storageapiv1.StorageClusterSpec{
+			Nodelist: []storageapiv1.NodeInfo{{HostName: "node1"}},
+		},
+	}
+}
+
+func TestStorageClusterControllerCreate(t *testing.T) {
+	f := newClusterFixture(newStorageCluster())
+
+	if err := f.controller.reconcileCluster(context.TODO(), "rook-ceph", "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cephcluster, err := f.rookclnt.CephV1().CephClusters("rook-ceph").Get(context.TODO(), "c1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Ceph cluster not created: %v", err)
+	}
+	if len(cephcluster.Spec.Storage.Nodes) != 1 || cephcluster.Spec.Storage.Nodes[0].Name != "node1" {
+		t.Errorf("unexpected storage nodes %v", cephcluster.Spec.Storage.Nodes)
+	}
+	owner := metav1.GetControllerOf(cephcluster)
+	if owner == nil || owner.UID != "c1-uid" || owner.Kind != "StorageCluster" {
+		t.Errorf("unexpected controller reference %v", owner)
+	}
+
+	cluster := f.getCluster(t)
+	if !hasFinalizer(cluster.ObjectMeta.Finalizers, storageClusterFinalizer) {
+		t.Errorf("expected finalizer, got %v", cluster.ObjectMeta.Finalizers)
+	}
+}
+
+func TestStorageClusterControllerStatus(t *testing.T) {
+	cluster := newStorageCluster()
+	cluster.ObjectMeta.Finalizers = []string{storageClusterFinalizer}
+	f := newClusterFixture(cluster)
+	_, err := f.rookclnt.CephV1().CephClusters("rook-ceph").Create(context.TODO(), &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "rook-ceph"},
+		Status: cephv1.ClusterStatus{
+			Phase:   cephv1.ConditionReady,
+			State:   cephv1.ClusterStateCreated,
+			Message: "Cluster created successfully",
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.controller.reconcileCluster(context.TODO(), "rook-ceph", "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status := f.getCluster(t).Status
+	expected := storageapiv1.StorageClusterStatus{
+		Phase:   storageapiv1.ClusterPhaseReady,
+		State:   storageapiv1.ClusterStateCreated,
+		Message: "Cluster created successfully",
+	}
+	if status != expected {
+		t.Errorf("expected status %+v, got %+v", expected, status)
+	}
+}
+
+func TestStorageClusterControllerFailure(t *testing.T) {
+	cluster := newStorageCluster()
+	cluster.Spec.StorageClusterID = "fsid"
+	f := newClusterFixture(cluster)
+
+	if err := f.controller.reconcileCluster(context.TODO(), "rook-ceph", "c1"); err == nil {
+		t.Fatalf("expected error for external cluster without connection settings")
+	}
+	status := f.getCluster(t).Status
+	if status.Phase != storageapiv1.ClusterPhaseFailure || len(status.Message) == 0 {
+		t.Errorf("expected failure status, got %+v", status)
+	}
+}
+
+func TestStorageClusterControllerDelete(t *testing.T) {
+	cluster := newStorageCluster()
+	now := metav1.Now()
+	cluster.ObjectMeta.DeletionTimestamp = &now
+	cluster.ObjectMeta.Finalizers = []string{storageClusterFinalizer, "other"}
+	f := newClusterFixture(cluster)
+	_, err := f.rookclnt.CephV1().CephClusters("rook-ceph").Create(context.TODO(), &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "rook-ceph"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.controller.reconcileCluster(context.TODO(), "rook-ceph", "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = f.rookclnt.CephV1().CephClusters("rook-ceph").Get(context.TODO(), "c1", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected Ceph cluster deleted, got %v", err)
+	}
+	finalizers := f.getCluster(t).ObjectMeta.Finalizers
+	if len(finalizers) != 1 || finalizers[0] != "other" {
+		t.Errorf("expected only the other finalizer left, got %v", finalizers)
+	}
+
+	// The Ceph cluster is already gone when the finalizer is retried.
+	if err := f.controller.reconcileCluster(context.TODO(), "rook-ceph", "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	storageclient "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned"
+	storagescheme "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/scheme"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions/storageapi/v1"
+	storagelisters "github.com/murali-bashyam/rookclient/pkg/client/listers/storageapi/v1"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// Finalizer keeping a storage pool until its Ceph block pool is deleted.
+	storagePoolFinalizer string = "storage.rookclient.io/storagepool"
+
+	// Index of the storage pools by the namespace/name of their Ceph block pool.
+	cephBlockPoolIndex string = "cephblockpool"
+)
+
+// StoragePoolController reconciles StoragePool objects into Ceph block
+// pools in the namespace of their storage cluster, reverting any change
+// made directly to a Ceph block pool.
+type StoragePoolController struct {
+	controller
+
+	storageclnt storageclient.Interface
+	rookclnt    rookclient.Interface
+	lister      storagelisters.StoragePoolLister
+	indexer     cache.Indexer
+	recorder    record.EventRecorder
+	// Mapping profile of the Ceph block pool informer, the pools are
+	// created with the profile they are reverse mapped with.
+	profile *storageapiv1.MappingProfile
+}
+
+func cephBlockPoolKey(blockpool *storageapiv1.StoragePool) string {
+	return blockpool.Spec.ClusterID + "/" + blockpool.ObjectMeta.Name
+}
+
+// poolOwner returns the namespace/name of the storage pool recorded as the
+// owner of the Ceph block pools it creates.
+func poolOwner(blockpool *storageapiv1.StoragePool) string {
+	return blockpool.ObjectMeta.Namespace + "/" + blockpool.ObjectMeta.Name
+}
+
+// ownedByOther reports whether the Ceph block pool, read back in actual,
+// was created for another storage pool than blockpool.
+func ownedByOther(blockpool *storageapiv1.StoragePool, actual *storageapiv1.StoragePool) (string, bool) {
+	owner := actual.ObjectMeta.Annotations[storageapiv1.PoolOwnerAnnotation]
+	return owner, len(owner) != 0 && owner != poolOwner(blockpool)
+}
+
+// newEventRecorder returns a recorder posting the events of component to
+// the Kubernetes API.
+func newEventRecorder(kubeclnt kubernetes.Interface, component string) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclnt.CoreV1().Events("")})
+	return broadcaster.NewRecorder(storagescheme.Scheme, corev1.EventSource{Component: component})
+}
+
+// NewStoragePoolController returns a controller watching the storage pools
+// of poolInformer and the Ceph block pools of cephInformer. A nil log
+// discards the messages of the controller.
+func NewStoragePoolController(storageclnt storageclient.Interface, rookclnt rookclient.Interface,
+	kubeclnt kubernetes.Interface, poolInformer storageinformers.StoragePoolInformer,
+	cephInformer *storageapiv1.StoragePoolInformer, log logr.Logger) *StoragePoolController {
+	c := &StoragePoolController{
+		storageclnt: storageclnt,
+		rookclnt:    rookclnt,
+		lister:      poolInformer.Lister(),
+		indexer:     poolInformer.Informer().GetIndexer(),
+		recorder:    newEventRecorder(kubeclnt, "storagepool-controller"),
+		profile:     cephInformer.Profile(),
+	}
+	c.controller = newController("storagepool", c.reconcilePool, log)
+	c.synced = append(c.synced, poolInformer.Informer().HasSynced, cephInformer.Informer().HasSynced)
+
+	poolInformer.Informer().AddIndexers(cache.Indexers{
+		cephBlockPoolIndex: func(obj interface{}) ([]string, error) {
+			blockpool, ok := obj.(*storageapiv1.StoragePool)
+			if !ok {
+				return nil, nil
+			}
+			return []string{cephBlockPoolKey(blockpool)}, nil
+		},
+	})
+	poolInformer.Informer().AddEventHandler(c.eventHandler())
+	cephInformer.AddEventHandler(storageapiv1.StoragePoolEventHandlerFuncs{
+		AddFunc: c.enqueueOwner,
+		UpdateFunc: func(oldPool, newPool *storageapiv1.StoragePool) {
+			c.enqueueOwner(newPool)
+		},
+		DeleteFunc: c.enqueueOwner,
+	})
+	return c
+}
+
+// enqueueOwner queues the storage pools of a changed Ceph block pool.
+func (c *StoragePoolController) enqueueOwner(cephpool *storageapiv1.StoragePool) {
+	owners, err := c.indexer.ByIndex(cephBlockPoolIndex, cephBlockPoolKey(cephpool))
+	if err != nil {
+		return
+	}
+	for _, owner := range owners {
+		c.enqueue(owner)
+	}
+}
+
+// poolDrift returns the policy settings of the Ceph block pool, reverse
+// mapped in actual, that differ from the storage pool.
+func poolDrift(blockpool *storageapiv1.StoragePool, actual *storageapiv1.StoragePool) []string {
+	var drift []string
+
+	desired := blockpool.Spec
+	if actual.Spec.DurabilityPolicy.FailureDomain != desired.DurabilityPolicy.FailureDomain {
+		drift = append(drift, "failure domain")
+	}
+	if actual.Spec.DurabilityPolicy.DurabilityClass != desired.DurabilityPolicy.DurabilityClass {
+		drift = append(drift, "durability class")
+	}
+	if actual.Spec.DurabilityPolicy.DurabilityLevel != desired.DurabilityPolicy.DurabilityLevel {
+		drift = append(drift, "durability level")
+	}
+	if actual.Spec.PerfPolicy.IoPerfClass != desired.PerfPolicy.IoPerfClass {
+		drift = append(drift, "performance class")
+	}
+	if actual.Spec.Quota.Cmp(desired.Quota) != 0 || actual.Spec.QuotaObjects != desired.QuotaObjects {
+		drift = append(drift, "quota")
+	}
+	return drift
+}
+
+func (c *StoragePoolController) reconcilePool(ctx context.Context, namespace, name string) error {
+	blockpool, err := c.lister.StoragePools(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	blockpool = blockpool.DeepCopy()
+	pools := &storageapiv1.StoragePools{
+		Namespace: blockpool.Spec.ClusterID,
+		Client:    c.rookclnt,
+		Log:       c.log,
+		Profile:   c.profile,
+	}
+
+	if blockpool.ObjectMeta.DeletionTimestamp != nil {
+		return c.finalizePool(ctx, pools, blockpool)
+	}
+	if !hasFinalizer(blockpool.ObjectMeta.Finalizers, storagePoolFinalizer) {
+		blockpool.ObjectMeta.Finalizers = append(blockpool.ObjectMeta.Finalizers, storagePoolFinalizer)
+		blockpool, err = c.storageclnt.StorageV1().StoragePools(namespace).Update(ctx, blockpool, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Storage pools admitted without the webhook may leave the policies
+	// to their defaults. Storage pools of the same name in different
+	// namespaces map to the same Ceph block pool, only its owner syncs it.
+	desired := blockpool.DeepCopy()
+	desired.Spec.SetDefaults()
+	desired.ObjectMeta.Annotations = map[string]string{storageapiv1.PoolOwnerAnnotation: poolOwner(blockpool)}
+	status := blockpool.Status.DeepCopy()
+	actual, err := pools.GetContext(ctx, name)
+	if err == nil {
+		if owner, other := ownedByOther(blockpool, actual); other {
+			err = &storageapiv1.OwnerConflictError{Kind: "Ceph block pool", Name: blockpool.Spec.ClusterID + "/" + name, Owner: owner}
+		}
+	}
+	if apierrors.IsNotFound(err) {
+		err = pools.CreateContext(ctx, desired)
+		if err == nil {
+			c.recorder.Eventf(blockpool, corev1.EventTypeNormal, "Created", "Created Ceph block pool %s/%s",
+				blockpool.Spec.ClusterID, name)
+		}
+	} else if err == nil {
+		drift := poolDrift(desired, actual)
+		// Ceph block pools created before the owner was recorded are
+		// adopted.
+		_, owned := actual.ObjectMeta.Annotations[storageapiv1.PoolOwnerAnnotation]
+		if len(drift) != 0 || !owned {
+			err = pools.UpdateContext(ctx, desired)
+		}
+		// Ceph block pools differing from an already applied storage pool
+		// were changed directly, a new generation is just applied.
+		if err == nil && len(drift) != 0 && blockpool.Status.ObservedGeneration == blockpool.ObjectMeta.Generation {
+			message := fmt.Sprintf("Ceph block pool %s/%s changed outside of the storage pool, reverted %s",
+				blockpool.Spec.ClusterID, name, strings.Join(drift, ", "))
+			c.recorder.Event(blockpool, corev1.EventTypeWarning, "DriftDetected", message)
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               storageapiv1.PoolConditionDrifted,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: blockpool.ObjectMeta.Generation,
+				Reason:             "DriftReverted",
+				Message:            message,
+			})
+		} else if len(drift) == 0 {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               storageapiv1.PoolConditionDrifted,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: blockpool.ObjectMeta.Generation,
+				Reason:             "InSync",
+				Message:            "Ceph block pool matches the storage pool policies",
+			})
+		}
+	}
+	if err != nil {
+		c.recorder.Eventf(blockpool, corev1.EventTypeWarning, "SyncFailed", "Failed to sync Ceph block pool: %v", err)
+		status.Phase = storageapiv1.PoolPhaseFailure
+		if statusErr := c.updateStatus(ctx, blockpool, status); statusErr != nil {
+			c.log.Error(statusErr, "Failed to update status of storage pool", "namespace", namespace, "name", name)
+		}
+		return err
+	}
+
+	actual, err = pools.GetContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	status.Phase = actual.Status.Phase
+	status.ObservedGeneration = blockpool.ObjectMeta.Generation
+	return c.updateStatus(ctx, blockpool, status)
+}
+
+// finalizePool deletes the Ceph block pool and releases the storage pool.
+// A Ceph block pool owned by another storage pool is left in place.
+func (c *StoragePoolController) finalizePool(ctx context.Context, pools *storageapiv1.StoragePools,
+	blockpool *storageapiv1.StoragePool) error {
+	if !hasFinalizer(blockpool.ObjectMeta.Finalizers, storagePoolFinalizer) {
+		return nil
+	}
+	name := blockpool.ObjectMeta.Name
+	actual, err := pools.GetContext(ctx, name)
+	if err == nil {
+		if owner, other := ownedByOther(blockpool, actual); other {
+			c.recorder.Eventf(blockpool, corev1.EventTypeWarning, "DeletionSkipped",
+				"Ceph block pool %s/%s is owned by storage pool %s, not deleted", blockpool.Spec.ClusterID, name, owner)
+		} else {
+			err = pools.DeleteContext(ctx, name)
+		}
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	blockpool.ObjectMeta.Finalizers = removeFinalizer(blockpool.ObjectMeta.Finalizers, storagePoolFinalizer)
+	_, err = c.storageclnt.StorageV1().StoragePools(blockpool.ObjectMeta.Namespace).Update(ctx, blockpool, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *StoragePoolController) updateStatus(ctx context.Context, blockpool *storageapiv1.StoragePool,
+	status *storageapiv1.StoragePoolStatus) error {
+	if equality.Semantic.DeepEqual(blockpool.Status, *status) {
+		return nil
+	}
+	blockpool.Status = *status
+	_, err := c.storageclnt.StorageV1().StoragePools(blockpool.ObjectMeta.Namespace).UpdateStatus(ctx, blockpool, metav1.UpdateOptions{})
+	return err
+}
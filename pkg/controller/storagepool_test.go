@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	storagefake "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/fake"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+type poolFixture struct {
+	storageclnt *storagefake.Clientset
+	rookclnt    *rookfake.Clientset
+	recorder    *record.FakeRecorder
+	controller  *StoragePoolController
+}
+
+// newPoolFixture returns a controller whose cache holds blockpool, the
+// cache is not updated by the reconciles.
+func newPoolFixture(blockpool *storageapiv1.StoragePool, cephpools ...*cephv1.CephBlockPool) *poolFixture {
+	f := &poolFixture{
+		storageclnt: storagefake.NewSimpleClientset(blockpool),
+		rookclnt:    rookfake.NewSimpleClientset(),
+		recorder:    record.NewFakeRecorder(10),
+	}
+	for _, cephpool := range cephpools {
+		f.rookclnt.Tracker().Add(cephpool)
+	}
+	informers := storageinformers.NewSharedInformerFactory(f.storageclnt, 0)
+	poolInformer := informers.Storage().V1().StoragePools()
+	cephInformer := storageapiv1.NewSharedInformerFactory(f.rookclnt, "", 0).StoragePools()
+	f.controller = NewStoragePoolController(f.storageclnt, f.rookclnt, kubefake.NewSimpleClientset(),
+		poolInformer, cephInformer, nil)
+	f.controller.recorder = f.recorder
+	poolInformer.Informer().GetIndexer().Add(blockpool)
+	return f
+}
+
+func (f *poolFixture) reconcile(t *testing.T) *storageapiv1.StoragePool {
+	if err := f.controller.reconcilePool(context.TODO(), "apps", "bpool1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blockpool, err := f.storageclnt.StorageV1().StoragePools("apps").Get(context.TODO(), "bpool1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return blockpool
+}
+
+func (f *poolFixture) getCephPool(t *testing.T) *cephv1.CephBlockPool {
+	cephpool, err := f.rookclnt.CephV1().CephBlockPools("rook-ceph").Get(context.TODO(), "bpool1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cephpool
+}
+
+func (f *poolFixture) events() []string {
+	var events []string
+
+	for {
+		select {
+		case event := <-f.recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func newStoragePool(generation, observedGeneration int64) *storageapiv1.StoragePool {
+	return &storageapiv1.StoragePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "bpool1",
+			Namespace:  "apps",
+			Generation: generation,
+		},
+		Spec: storageapiv1.StoragePoolSpec{
+			ClusterID: "rook-ceph",
+			DurabilityPolicy: storageapiv1.StoragePolicyDurability{
+				FailureDomain:   storageapiv1.FailureDomainHost,
+				DurabilityClass: storageapiv1.DurabilityClassReplicated,
+				DurabilityLevel: storageapiv1.DurabilityLevelNormal,
+			},
+			PerfPolicy: storageapiv1.StoragePolicyPerformance{
+				IoPerfClass: storageapiv1.DevMedium,
+			},
+		},
+		Status: storageapiv1.StoragePoolStatus{
+			ObservedGeneration: observedGeneration,
+		},
+	}
+}
+
+func newCephPool(size uint) *cephv1.CephBlockPool {
+	maxSize := "0"
+	maxObjects := uint64(0)
+	return &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "bpool1",
+			Namespace:   "rook-ceph",
+			Annotations: map[string]string{storageapiv1.PoolOwnerAnnotation: "apps/bpool1"},
+		},
+		Spec: cephv1.PoolSpec{
+			FailureDomain: "host",
+			DeviceClass:   "ssd",
+			Replicated:    cephv1.ReplicatedSpec{Size: size},
+			Quotas:        cephv1.QuotaSpec{MaxSize: &maxSize, MaxObjects: &maxObjects},
+		},
+		Status: &cephv1.CephBlockPoolStatus{Phase: "Ready"},
+	}
+}
+
+func TestStoragePoolControllerCreate(t *testing.T) {
+	f := newPoolFixture(newStoragePool(1, 0))
+
+	blockpool := f.reconcile(t)
+	cephpool := f.getCephPool(t)
+	if cephpool.Spec.Replicated.Size != 3 || cephpool.Spec.DeviceClass != "ssd" {
+		t.Errorf("unexpected Ceph block pool spec %+v", cephpool.Spec)
+	}
+	if owner := cephpool.ObjectMeta.Annotations[storageapiv1.PoolOwnerAnnotation]; owner != "apps/bpool1" {
+		t.Errorf("expected owner apps/bpool1, got %q", owner)
+	}
+	if !hasFinalizer(blockpool.ObjectMeta.Finalizers, storagePoolFinalizer) {
+		t.Errorf("expected finalizer, got %v", blockpool.ObjectMeta.Finalizers)
+	}
+	if blockpool.Status.ObservedGeneration != 1 {
+		t.Errorf("expected observed generation 1, got %d", blockpool.Status.ObservedGeneration)
+	}
+	events := f.events()
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Normal Created") {
+		t.Errorf("expected a Created event, got %v", events)
+	}
+}
+
+func TestStoragePoolControllerDrift(t *testing.T) {
+	blockpool := newStoragePool(1, 1)
+	blockpool.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	f := newPoolFixture(blockpool, newCephPool(2))
+
+	blockpool = f.reconcile(t)
+	if size := f.getCephPool(t).Spec.Replicated.Size; size != 3 {
+		t.Errorf("expected replica size reverted to 3, got %d", size)
+	}
+	condition := meta.FindStatusCondition(blockpool.Status.Conditions, storageapiv1.PoolConditionDrifted)
+	if condition == nil || condition.Status != metav1.ConditionTrue || !strings.Contains(condition.Message, "durability level") {
+		t.Errorf("expected drifted condition, got %+v", condition)
+	}
+	if blockpool.Status.Phase != storageapiv1.PoolPhaseReady {
+		t.Errorf("expected phase Ready, got %s", blockpool.Status.Phase)
+	}
+	events := f.events()
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning DriftDetected") {
+		t.Errorf("expected a DriftDetected event, got %v", events)
+	}
+}
+
+func TestStoragePoolControllerSpecChange(t *testing.T) {
+	blockpool := newStoragePool(2, 1)
+	blockpool.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	f := newPoolFixture(blockpool, newCephPool(2))
+
+	blockpool = f.reconcile(t)
+	if size := f.getCephPool(t).Spec.Replicated.Size; size != 3 {
+		t.Errorf("expected replica size updated to 3, got %d", size)
+	}
+	if meta.IsStatusConditionTrue(blockpool.Status.Conditions, storageapiv1.PoolConditionDrifted) {
+		t.Errorf("expected no drift for a new generation, got %+v", blockpool.Status.Conditions)
+	}
+	if blockpool.Status.ObservedGeneration != 2 {
+		t.Errorf("expected observed generation 2, got %d", blockpool.Status.ObservedGeneration)
+	}
+	if events := f.events(); len(events) != 0 {
+		t.Errorf("expected no events, got %v", events)
+	}
+}
+
+func TestStoragePoolControllerInSync(t *testing.T) {
+	blockpool := newStoragePool(1, 1)
+	blockpool.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	f := newPoolFixture(blockpool, newCephPool(3))
+
+	blockpool = f.reconcile(t)
+	condition := meta.FindStatusCondition(blockpool.Status.Conditions, storageapiv1.PoolConditionDrifted)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected in sync condition, got %+v", condition)
+	}
+	for _, action := range f.rookclnt.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("expected Ceph block pool left untouched, got %v", action)
+		}
+	}
+}
+
+func TestStoragePoolControllerDelete(t *testing.T) {
+	blockpool := newStoragePool(1, 1)
+	now := metav1.Now()
+	blockpool.ObjectMeta.DeletionTimestamp = &now
+	blockpool.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	f := newPoolFixture(blockpool, newCephPool(3))
+
+	blockpool = f.reconcile(t)
+	_, err := f.rookclnt.CephV1().CephBlockPools("rook-ceph").Get(context.TODO(), "bpool1", metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected Ceph block pool deleted, got %v", err)
+	}
+	if len(blockpool.ObjectMeta.Finalizers) != 0 {
+		t.Errorf("expected finalizer removed, got %v", blockpool.ObjectMeta.Finalizers)
+	}
+}
+
+func TestStoragePoolControllerAdopt(t *testing.T) {
+	blockpool := newStoragePool(1, 1)
+	blockpool.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	cephpool := newCephPool(3)
+	cephpool.ObjectMeta.Annotations = nil
+	f := newPoolFixture(blockpool, cephpool)
+
+	f.reconcile(t)
+	if owner := f.getCephPool(t).ObjectMeta.Annotations[storageapiv1.PoolOwnerAnnotation]; owner != "apps/bpool1" {
+		t.Errorf("expected the Ceph block pool adopted by apps/bpool1, got %q", owner)
+	}
+}
+
+func TestStoragePoolControllerOwnerConflict(t *testing.T) {
+	// Storage pools of the same name and cluster in two namespaces, the
+	// Ceph block pool was created for the one in apps.
+	blockpool := newStoragePool(1, 1)
+	blockpool.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	other := newStoragePool(1, 0)
+	other.ObjectMeta.Namespace = "team-a"
+	other.ObjectMeta.Finalizers = []string{storagePoolFinalizer}
+	other.Spec.DurabilityPolicy.DurabilityLevel = storageapiv1.DurabilityLevelHigh
+	f := newPoolFixture(blockpool, newCephPool(3))
+	f.storageclnt.Tracker().Add(other)
+	f.controller.indexer.Add(other)
+
+	err := f.controller.reconcilePool(context.TODO(), "team-a", "bpool1")
+	if !errors.Is(err, storageapiv1.ErrOwnerConflict) || !isPermanent(err) {
+		t.Fatalf("expected a permanent owner conflict, got %v", err)
+	}
+	if size := f.getCephPool(t).Spec.Replicated.Size; size != 3 {
+		t.Errorf("expected the Ceph block pool of apps/bpool1 untouched, got replica size %d", size)
+	}
+	other, err = f.storageclnt.StorageV1().StoragePools("team-a").Get(context.TODO(), "bpool1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.Status.Phase != storageapiv1.PoolPhaseFailure {
+		t.Errorf("expected phase Failure, got %s", other.Status.Phase)
+	}
+	events := f.events()
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning SyncFailed") {
+		t.Errorf("expected a SyncFailed event, got %v", events)
+	}
+
+	// Deleting the storage pool that does not own the Ceph block pool
+	// keeps it.
+	now := metav1.Now()
+	other.ObjectMeta.DeletionTimestamp = &now
+	f.controller.indexer.Update(other)
+	if err := f.controller.reconcilePool(context.TODO(), "team-a", "bpool1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.getCephPool(t)
+	other, err = f.storageclnt.StorageV1().StoragePools("team-a").Get(context.TODO(), "bpool1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(other.ObjectMeta.Finalizers) != 0 {
+		t.Errorf("expected finalizer removed, got %v", other.ObjectMeta.Finalizers)
+	}
+	events = f.events()
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning DeletionSkipped") {
+		t.Errorf("expected a DeletionSkipped event, got %v", events)
+	}
+
+	// The owner still syncs it.
+	f.reconcile(t)
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	storageclient "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions/storageapi/v1"
+	storagelisters "github.com/murali-bashyam/rookclient/pkg/client/listers/storageapi/v1"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// Finalizer keeping a storage volume until its storage class is deleted.
+	storageVolumeFinalizer string = "storage.rookclient.io/storagevolume"
+
+	// Index of the storage volumes and claims by the namespace of the
+	// storage volume and the storage class name.
+	storageClassIndex string = "storageclass"
+
+	// Storage class annotation of claims predating spec.storageClassName.
+	betaStorageClassAnnotation string = "volume.beta.kubernetes.io/storage-class"
+)
+
+// StorageVolumeController reconciles StorageVolume objects into storage
+// classes and reports the claims using them.
+type StorageVolumeController struct {
+	controller
+
+	storageclnt  storageclient.Interface
+	kubeclnt     kubernetes.Interface
+	lister       storagelisters.StorageVolumeLister
+	indexer      cache.Indexer
+	claimIndexer cache.Indexer
+	recorder     record.EventRecorder
+}
+
+// claimStorageClass returns the storage class name requested by the claim.
+func claimStorageClass(claim *corev1.PersistentVolumeClaim) string {
+	if class, ok := claim.ObjectMeta.Annotations[betaStorageClassAnnotation]; ok {
+		return class
+	}
+	if claim.Spec.StorageClassName != nil {
+		return *claim.Spec.StorageClassName
+	}
+	return ""
+}
+
+// storageClassKey is the storageClassIndex key of the storage class of the
+// volume in namespace.
+func storageClassKey(namespace string, class string) string {
+	return namespace + "/" + class
+}
+
+// claimStorageClassKeys returns the storageClassIndex keys of the claim,
+// none if its storage class is not the one of a storage volume.
+func claimStorageClassKeys(claim *corev1.PersistentVolumeClaim) []string {
+	class := claimStorageClass(claim)
+	namespace, _, ok := storageapiv1.ParseStorageClassName(class)
+	if !ok {
+		return nil
+	}
+	return []string{storageClassKey(namespace, class)}
+}
+
+// NewStorageVolumeController returns a controller watching the storage
+// volumes of volumeInformer and the claims of claimInformer. A nil log
+// discards the messages of the controller.
+func NewStorageVolumeController(storageclnt storageclient.Interface, kubeclnt kubernetes.Interface,
+	volumeInformer storageinformers.StorageVolumeInformer,
+	claimInformer coreinformers.PersistentVolumeClaimInformer, log logr.Logger) *StorageVolumeController {
+	c := &StorageVolumeController{
+		storageclnt:  storageclnt,
+		kubeclnt:     kubeclnt,
+		lister:       volumeInformer.Lister(),
+		indexer:      volumeInformer.Informer().GetIndexer(),
+		claimIndexer: claimInformer.Informer().GetIndexer(),
+		recorder:     newEventRecorder(kubeclnt, "storagevolume-controller"),
+	}
+	c.controller = newController("storagevolume", c.reconcileVolume, log)
+	c.synced = append(c.synced, volumeInformer.Informer().HasSynced, claimInformer.Informer().HasSynced)
+
+	volumeInformer.Informer().AddIndexers(cache.Indexers{
+		storageClassIndex: func(obj interface{}) ([]string, error) {
+			volume, ok := obj.(*storageapiv1.StorageVolume)
+			if !ok {
+				return nil, nil
+			}
+			class := storageapiv1.StorageClassName(volume.ObjectMeta.Namespace, volume.ObjectMeta.Name)
+			return []string{storageClassKey(volume.ObjectMeta.Namespace, class)}, nil
+		},
+	})
+	claimInformer.Informer().AddIndexers(cache.Indexers{
+		storageClassIndex: func(obj interface{}) ([]string, error) {
+			claim, ok := obj.(*corev1.PersistentVolumeClaim)
+			if !ok {
+				return nil, nil
+			}
+			return claimStorageClassKeys(claim), nil
+		},
+	})
+	volumeInformer.Informer().AddEventHandler(c.eventHandler())
+	claimInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueClaimVolume,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueClaimVolume(newObj)
+		},
+		DeleteFunc: c.enqueueClaimVolume,
+	})
+	return c
+}
+
+// enqueueClaimVolume queues the storage volumes of the claim's storage class.
+func (c *StorageVolumeController) enqueueClaimVolume(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	claim, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	for _, key := range claimStorageClassKeys(claim) {
+		volumes, err := c.indexer.ByIndex(storageClassIndex, key)
+		if err != nil {
+			return
+		}
+		for _, volume := range volumes {
+			c.enqueue(volume)
+		}
+	}
+}
+
+// claimUsage returns the claims of the storage class of the volume in
+// namespace, the number of them bound and the total capacity they request.
+func (c *StorageVolumeController) claimUsage(namespace string, class string) (int, int32, resource.Quantity, error) {
+	var bound int32
+	var capacity resource.Quantity
+
+	claims, err := c.claimIndexer.ByIndex(storageClassIndex, storageClassKey(namespace, class))
+	if err != nil {
+		return 0, 0, capacity, err
+	}
+	for _, obj := range claims {
+		claim, ok := obj.(*corev1.PersistentVolumeClaim)
+		if !ok {
+			continue
+		}
+		if claim.Status.Phase == corev1.ClaimBound {
+			bound++
+		}
+		if request, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			capacity.Add(request)
+		}
+	}
+	return len(claims), bound, capacity, nil
+}
+
+func (c *StorageVolumeController) reconcileVolume(ctx context.Context, namespace, name string) error {
+	volume, err := c.lister.StorageVolumes(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	volume = volume.DeepCopy()
+	volumes := &storageapiv1.StorageVolumes{
+		Namespace:  namespace,
+		KubeClient: c.kubeclnt,
+		Log:        c.log,
+	}
+	class := storageapiv1.StorageClassName(namespace, name)
+	claims, bound, capacity, err := c.claimUsage(namespace, class)
+	if err != nil {
+		return err
+	}
+	status := volume.Status.DeepCopy()
+	status.BoundClaims = bound
+	status.RequestedCapacity = capacity
+
+	if volume.ObjectMeta.DeletionTimestamp != nil {
+		if !hasFinalizer(volume.ObjectMeta.Finalizers, storageVolumeFinalizer) {
+			return nil
+		}
+		// The claims are requeuing the volume as they go away.
+		if claims != 0 {
+			status.Reason = fmt.Sprintf("Storage class %s is used by %d persistent volume claims", class, claims)
+			if status.Reason != volume.Status.Reason {
+				c.recorder.Event(volume, corev1.EventTypeWarning, "DeletionBlocked", status.Reason)
+			}
+			return c.updateStatus(ctx, volume, status)
+		}
+		err = volumes.DeleteContext(ctx, name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		volume.ObjectMeta.Finalizers = removeFinalizer(volume.ObjectMeta.Finalizers, storageVolumeFinalizer)
+		_, err = c.storageclnt.StorageV1().StorageVolumes(namespace).Update(ctx, volume, metav1.UpdateOptions{})
+		return err
+	}
+	if !hasFinalizer(volume.ObjectMeta.Finalizers, storageVolumeFinalizer) {
+		volume.ObjectMeta.Finalizers = append(volume.ObjectMeta.Finalizers, storageVolumeFinalizer)
+		volume, err = c.storageclnt.StorageV1().StorageVolumes(namespace).Update(ctx, volume, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = volumes.GetContext(ctx, name)
+	if apierrors.IsNotFound(err) {
+		_, _, err = volumes.CreateContext(ctx, volume.DeepCopy())
+		if err == nil {
+			c.recorder.Eventf(volume, corev1.EventTypeNormal, "Created", "Created storage class %s", class)
+		}
+	} else if err == nil {
+		_, err = volumes.UpdateContext(ctx, volume.DeepCopy())
+	}
+	if err != nil {
+		c.recorder.Eventf(volume, corev1.EventTypeWarning, "SyncFailed", "Failed to apply storage class %s: %v", class, err)
+		status.Phase = storageapiv1.VolumeFailed
+		status.Reason = err.Error()
+		if statusErr := c.updateStatus(ctx, volume, status); statusErr != nil {
+			c.log.Error(statusErr, "Failed to update status of storage volume", "namespace", namespace, "name", name)
+		}
+		return err
+	}
+	status.Phase = storageapiv1.VolumeCreated
+	status.Reason = ""
+	return c.updateStatus(ctx, volume, status)
+}
+
+func (c *StorageVolumeController) updateStatus(ctx context.Context, volume *storageapiv1.StorageVolume,
+	status *storageapiv1.StorageVolumeStatus) error {
+	if equality.Semantic.DeepEqual(volume.Status, *status) {
+		return nil
+	}
+	volume.Status = *status
+	_, err := c.storageclnt.StorageV1().StorageVolumes(volume.ObjectMeta.Namespace).UpdateStatus(ctx, volume, metav1.UpdateOptions{})
+	return err
+}
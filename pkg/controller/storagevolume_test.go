@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	storagefake "github.com/murali-bashyam/rookclient/pkg/client/clientset/versioned/fake"
+	storageinformers "github.com/murali-bashyam/rookclient/pkg/client/informers/externalversions"
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+type volumeFixture struct {
+	storageclnt *storagefake.Clientset
+	kubeclnt    *kubefake.Clientset
+	recorder    *record.FakeRecorder
+	controller  *StorageVolumeController
+}
+
+// newVolumeFixture returns a controller whose caches hold volume and
+// claims, the caches are not updated by the reconciles.
+func newVolumeFixture(volume *storageapiv1.StorageVolume, claims ...*corev1.PersistentVolumeClaim) *volumeFixture {
+	f := &volumeFixture{
+		storageclnt: storagefake.NewSimpleClientset(volume),
+		kubeclnt:    kubefake.NewSimpleClientset(),
+		recorder:    record.NewFakeRecorder(10),
+	}
+	informers := storageinformers.NewSharedInformerFactory(f.storageclnt, 0)
+	volumeInformer := informers.Storage().V1().StorageVolumes()
+	claimInformer := kubeinformers.NewSharedInformerFactory(f.kubeclnt, 0).Core().V1().PersistentVolumeClaims()
+	f.controller = NewStorageVolumeController(f.storageclnt, f.kubeclnt, volumeInformer, claimInformer, nil)
+	f.controller.recorder = f.recorder
+	volumeInformer.Informer().GetIndexer().Add(volume)
+	for _, claim := range claims {
+		claimInformer.Informer().GetIndexer().Add(claim)
+	}
+	return f
+}
+
+func (f *volumeFixture) reconcile(t *testing.T) (*storageapiv1.StorageVolume, error) {
+	err := f.controller.reconcileVolume(context.TODO(), "rook-ceph", "vol1")
+	volume, getErr := f.storageclnt.StorageV1().StorageVolumes("rook-ceph").Get(context.TODO(), "vol1", metav1.GetOptions{})
+	if getErr != nil {
+		t.Fatalf("unexpected error: %v", getErr)
+	}
+	return volume, err
+}
+
+func (f *volumeFixture) getStorageClass() (*storagev1.StorageClass, error) {
+	return f.kubeclnt.StorageV1().StorageClasses().Get(context.TODO(), "rook-ceph.vol1-block", metav1.GetOptions{})
+}
+
+func newVolume() *storageapiv1.StorageVolume {
+	return &storageapiv1.StorageVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vol1",
+			Namespace: "rook-ceph",
+		},
+		Spec: storageapiv1.StorageVolumeSpec{
+			VolumeType: storageapiv1.BlockVolume,
+			ClusterID:  "rook-ceph",
+			PoolID:     "bpool1",
+		},
+	}
+}
+
+func newClaim(name string, class string, size string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "apps",
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &class,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func TestStorageVolumeControllerCreate(t *testing.T) {
+	f := newVolumeFixture(newVolume(),
+		newClaim("claim1", "rook-ceph.vol1-block", "1Gi", corev1.ClaimBound),
+		newClaim("claim2", "rook-ceph.vol1-block", "512Mi", corev1.ClaimPending),
+		newClaim("claim3", "other", "10Gi", corev1.ClaimBound))
+
+	volume, err := f.reconcile(t)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	class, err := f.getStorageClass()
+	if err != nil {
+		t.Fatalf("storage class not created: %v", err)
+	}
+	if class.Parameters["pool"] != "bpool1" {
+		t.Errorf("unexpected storage class parameters %v", class.Parameters)
+	}
+	if !hasFinalizer(volume.ObjectMeta.Finalizers, storageVolumeFinalizer) {
+		t.Errorf("expected finalizer, got %v", volume.ObjectMeta.Finalizers)
+	}
+	if volume.Status.Phase != storageapiv1.VolumeCreated || len(volume.Status.Reason) != 0 {
+		t.Errorf("unexpected status %+v", volume.Status)
+	}
+	if volume.Status.BoundClaims != 1 {
+		t.Errorf("expected 1 bound claim, got %d", volume.Status.BoundClaims)
+	}
+	if volume.Status.RequestedCapacity.Cmp(resource.MustParse("1536Mi")) != 0 {
+		t.Errorf("expected 1536Mi requested, got %s", volume.Status.RequestedCapacity.String())
+	}
+	event := <-f.recorder.Events
+	if !strings.HasPrefix(event, "Normal Created") {
+		t.Errorf("unexpected event %q", event)
+	}
+}
+
+func TestStorageVolumeControllerClusterNamespace(t *testing.T) {
+	volume := newVolume()
+	volume.ObjectMeta.Namespace = "team-a"
+	f := newVolumeFixture(volume)
+
+	if err := f.controller.reconcileVolume(context.TODO(), "team-a", "vol1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	class, err := f.kubeclnt.StorageV1().StorageClasses().Get(context.TODO(), "team-a.vol1-block", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("storage class not created: %v", err)
+	}
+	if class.Provisioner != "rook-ceph.rbd.csi.ceph.com" {
+		t.Errorf("expected the provisioner of the cluster namespace, got %s", class.Provisioner)
+	}
+	for _, key := range []string{
+		"csi.storage.k8s.io/provisioner-secret-namespace",
+		"csi.storage.k8s.io/controller-expand-secret-namespace",
+		"csi.storage.k8s.io/node-stage-secret-namespace",
+	} {
+		if class.Parameters[key] != "rook-ceph" {
+			t.Errorf("expected %s rook-ceph, got %q", key, class.Parameters[key])
+		}
+	}
+	if class.ObjectMeta.Labels["storageapi.rookclient.io/namespace"] != "team-a" {
+		t.Errorf("expected the volume namespace label, got %v", class.ObjectMeta.Labels)
+	}
+}
+
+func TestStorageVolumeControllerFailure(t *testing.T) {
+	// A storage class of the same name not created for the volume.
+	f := newVolumeFixture(newVolume())
+	_, err := f.kubeclnt.StorageV1().StorageClasses().Create(context.TODO(), &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph.vol1-block"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	volume, err := f.reconcile(t)
+	if err == nil {
+		t.Fatalf("expected error for storage class owned by another volume")
+	}
+	if volume.Status.Phase != storageapiv1.VolumeFailed || volume.Status.Reason != err.Error() {
+		t.Errorf("expected failed status, got %+v", volume.Status)
+	}
+	event := <-f.recorder.Events
+	if !strings.HasPrefix(event, "Warning SyncFailed") {
+		t.Errorf("unexpected event %q", event)
+	}
+}
+
+func TestStorageVolumeControllerDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  []*corev1.PersistentVolumeClaim
+		deleted bool
+	}{
+		{
+			name:    "unused storage class",
+			claims:  nil,
+			deleted: true,
+		},
+		{
+			name:    "storage class used by claims",
+			claims:  []*corev1.PersistentVolumeClaim{newClaim("claim1", "rook-ceph.vol1-block", "1Gi", corev1.ClaimBound)},
+			deleted: false,
+		},
+		{
+			name:    "claims of a volume of the same name in another namespace",
+			claims:  []*corev1.PersistentVolumeClaim{newClaim("claim1", "other.vol1-block", "1Gi", corev1.ClaimBound)},
+			deleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volume := newVolume()
+			now := metav1.Now()
+			volume.ObjectMeta.DeletionTimestamp = &now
+			volume.ObjectMeta.Finalizers = []string{storageVolumeFinalizer}
+			f := newVolumeFixture(volume, tt.claims...)
+			volumes := &storageapiv1.StorageVolumes{Namespace: "rook-ceph", KubeClient: f.kubeclnt}
+			if _, _, err := volumes.Create(newVolume()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			volume, err := f.reconcile(t)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = f.getStorageClass()
+			if tt.deleted {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected storage class deleted, got %v", err)
+				}
+				if len(volume.ObjectMeta.Finalizers) != 0 {
+					t.Errorf("expected finalizer removed, got %v", volume.ObjectMeta.Finalizers)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected storage class kept, got %v", err)
+			}
+			if !hasFinalizer(volume.ObjectMeta.Finalizers, storageVolumeFinalizer) {
+				t.Errorf("expected finalizer kept, got %v", volume.ObjectMeta.Finalizers)
+			}
+			if !strings.Contains(volume.Status.Reason, "used by 1 persistent volume claims") {
+				t.Errorf("unexpected reason %q", volume.Status.Reason)
+			}
+			event := <-f.recorder.Events
+			if !strings.HasPrefix(event, "Warning DeletionBlocked") {
+				t.Errorf("unexpected event %q", event)
+			}
+		})
+	}
+}
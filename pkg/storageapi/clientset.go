@@ -2,14 +2,20 @@ package storageapi
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/go-logr/logr"
 	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 )
 
 type Clientset struct {
 	rookclnt *rookclient.Clientset
+	kubeclnt *kubernetes.Clientset
+	log      logr.Logger
+	profile  *storageapiv1.MappingProfile
 }
 
 func NewForConfig(config *restclient.Config) (*Clientset, error) {
@@ -17,17 +23,42 @@ func NewForConfig(config *restclient.Config) (*Clientset, error) {
 
 	rookclnt, err := rookclient.NewForConfig(config)
 	if err != nil {
-		fmt.Printf("Failed to initialize Rook Ceph clientset %v", err)
-		return nil, err
+		return nil, fmt.Errorf("Failed to initialize Rook Ceph clientset: %w", err)
 	}
 	cs.rookclnt = rookclnt
+
+	kubeclnt, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize Kubernetes clientset: %w", err)
+	}
+	cs.kubeclnt = kubeclnt
 	return &cs, nil
 }
 
+// WithLogger returns a copy of the clientset whose clients log their
+// operations to log with the operation, namespace and name keys. The
+// clientset returned by NewForConfig does not log.
+func (c *Clientset) WithLogger(log logr.Logger) *Clientset {
+	cs := *c
+	cs.log = log
+	return &cs
+}
+
+// WithProfile returns a copy of the clientset whose storage pool clients
+// and informers map the pool policies with profile. The clientset returned
+// by NewForConfig uses the DefaultMappingProfile.
+func (c *Clientset) WithProfile(profile *storageapiv1.MappingProfile) *Clientset {
+	cs := *c
+	cs.profile = profile
+	return &cs
+}
+
 func (c *Clientset) StorageClusters(namespace string) *storageapiv1.StorageClusters {
 	return &storageapiv1.StorageClusters{
-		Namespace: namespace,
-		Client:    c.rookclnt,
+		Namespace:  namespace,
+		Client:     c.rookclnt,
+		KubeClient: c.kubeclnt,
+		Log:        c.log,
 	}
 }
 
@@ -35,12 +66,24 @@ func (c *Clientset) StoragePools(namespace string) *storageapiv1.StoragePools {
 	return &storageapiv1.StoragePools{
 		Namespace: namespace,
 		Client:    c.rookclnt,
+		Log:       c.log,
+		Profile:   c.profile,
 	}
 }
 
 func (c *Clientset) StorageVolumes(namespace string) *storageapiv1.StorageVolumes {
 	return &storageapiv1.StorageVolumes{
-		Namespace: namespace,
-		Client:    c.rookclnt,
+		Namespace:  namespace,
+		Client:     c.rookclnt,
+		KubeClient: c.kubeclnt,
+		Log:        c.log,
 	}
 }
+
+// NewSharedInformerFactory returns an informer factory caching the storage
+// clusters and storage pools of namespace.
+func (c *Clientset) NewSharedInformerFactory(namespace string, resync time.Duration) *storageapiv1.SharedInformerFactory {
+	factory := storageapiv1.NewSharedInformerFactory(c.rookclnt, namespace, resync)
+	factory.Profile = c.profile
+	return factory
+}
@@ -1,205 +1,335 @@
 package v1
 
 import (
-	"fmt"
+	"context"
 
+	"github.com/go-logr/logr"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 )
 
+// PoolOwnerAnnotation records on a Ceph block pool the namespace/name of
+// the StoragePool object it was created for. Storage pools carrying it are
+// only applied to Ceph block pools without an owner or with the same one.
+const PoolOwnerAnnotation string = "storageapi.rookclient.io/owner"
+
 type BlockPoolInterface interface {
 	Create(blockpool *StoragePool) error
 	Update(blockpool *StoragePool) error
 	Delete(pool string) error
-	List() ([]StoragePool, error)
+	List(opts metav1.ListOptions, filter *StoragePoolFilter) (*StoragePoolList, error)
 	Get(pool string) (*StoragePool, error)
+	CreateContext(ctx context.Context, blockpool *StoragePool) error
+	UpdateContext(ctx context.Context, blockpool *StoragePool) error
+	DeleteContext(ctx context.Context, pool string) error
+	ListContext(ctx context.Context, opts metav1.ListOptions, filter *StoragePoolFilter) (*StoragePoolList, error)
+	GetContext(ctx context.Context, pool string) (*StoragePool, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (<-chan StoragePoolEvent, error)
 }
 
 type StoragePools struct {
 	Namespace string
-	Client    *rookclient.Clientset
+	Client    rookclient.Interface
+	// Log receives the operations of the client, nil discards them.
+	Log logr.Logger
+	// Profile maps the storage pool policies to the Ceph block pools and
+	// back, nil uses DefaultMappingProfile.
+	Profile *MappingProfile
 }
 
-func setupReplicatedSpec(pool *cephv1.CephBlockPool, blockpool *StoragePool) error {
-	var replicationFactor uint
-	var requireSafeReplicaSize bool
-
-	requireSafeReplicaSize = true
-	if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelLow {
-		replicationFactor = 1
-		requireSafeReplicaSize = false
-	} else if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelSemi {
-		replicationFactor = 2
-	} else if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelNormal {
-		replicationFactor = 3
-	} else if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelHigh {
-		replicationFactor = 4
-	} else {
-		ret := fmt.Errorf("No valid durability level specified, failed to create storage pool")
-		return ret
+var _ BlockPoolInterface = &StoragePools{}
+
+func setupReplicatedSpec(pool *cephv1.CephBlockPool, blockpool *StoragePool, profile *MappingProfile) error {
+	replicationFactor, ok := profile.Replicated[blockpool.Spec.DurabilityPolicy.DurabilityLevel]
+	if !ok {
+		return &InvalidPolicyError{Field: "spec.durabilitypolicy.redundancylevel",
+			Value: string(blockpool.Spec.DurabilityPolicy.DurabilityLevel), Reason: "not supported by replicated pools"}
 	}
 	pool.Spec.Replicated = cephv1.ReplicatedSpec{
 		Size:                   replicationFactor,
 		TargetSizeRatio:        1.0,
-		RequireSafeReplicaSize: requireSafeReplicaSize,
+		RequireSafeReplicaSize: replicationFactor > 1,
 	}
 	return nil
 }
 
-func setupErasureCodedSpec(pool *cephv1.CephBlockPool, blockpool *StoragePool) error {
-	var dataChunks uint
-	var codingChunks uint
-
-	if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelSemi {
-		dataChunks = 2
-		codingChunks = 1
-	} else if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelNormal {
-		dataChunks = 3
-		codingChunks = 2
-	} else if blockpool.Spec.DurabilityPolicy.DurabilityLevel == DurabilityLevelHigh {
-		dataChunks = 4
-		codingChunks = 3
-	} else {
-		ret := fmt.Errorf("No valid durability level specified, failed to create storage pool")
-		return ret
+func setupErasureCodedSpec(pool *cephv1.CephBlockPool, blockpool *StoragePool, profile *MappingProfile) error {
+	chunks, ok := profile.ErasureCoded[blockpool.Spec.DurabilityPolicy.DurabilityLevel]
+	if !ok {
+		return &InvalidPolicyError{Field: "spec.durabilitypolicy.redundancylevel",
+			Value: string(blockpool.Spec.DurabilityPolicy.DurabilityLevel), Reason: "not supported by erasure coded pools"}
 	}
 	pool.Spec.ErasureCoded = cephv1.ErasureCodedSpec{
-		CodingChunks: codingChunks,
-		DataChunks:   dataChunks,
+		CodingChunks: chunks.CodingChunks,
+		DataChunks:   chunks.DataChunks,
 	}
 
 	return nil
 }
 
-func (p *StoragePools) Create(blockpool *StoragePool) error {
-	var ret error
+// setupQuotas applies the pool quotas, a zero quota removes the limit.
+func setupQuotas(pool *cephv1.CephBlockPool, blockpool *StoragePool) error {
+	if blockpool.Spec.Quota.Sign() < 0 {
+		return &InvalidPolicyError{Field: "spec.quota", Value: blockpool.Spec.Quota.String(), Reason: "must not be negative"}
+	}
+	maxSize := blockpool.Spec.Quota.String()
+	maxObjects := blockpool.Spec.QuotaObjects
+	pool.Spec.Quotas = cephv1.QuotaSpec{
+		MaxSize:    &maxSize,
+		MaxObjects: &maxObjects,
+	}
+	return nil
+}
+
+// mapQuotas reverse maps the pool quotas, pools created with the
+// deprecated MaxBytes are reported as well.
+func mapQuotas(pool *cephv1.CephBlockPool) (resource.Quantity, uint64) {
+	var quota resource.Quantity
+	var quotaObjects uint64
+
+	if pool.Spec.Quotas.MaxSize != nil {
+		size, err := resource.ParseQuantity(*pool.Spec.Quotas.MaxSize)
+		if err == nil {
+			quota = size
+		}
+	} else if pool.Spec.Quotas.MaxBytes != nil {
+		quota = *resource.NewQuantity(int64(*pool.Spec.Quotas.MaxBytes), resource.BinarySI)
+	}
+	if pool.Spec.Quotas.MaxObjects != nil {
+		quotaObjects = *pool.Spec.Quotas.MaxObjects
+	}
+	return quota, quotaObjects
+}
+
+func invalidDurabilityClass(blockpool *StoragePool) error {
+	return &InvalidPolicyError{Field: "spec.durabilitypolicy.durabilityclass",
+		Value: string(blockpool.Spec.DurabilityPolicy.DurabilityClass), Reason: "one of replicated|erasurecoded"}
+}
+
+// cephPoolPolicy maps the failure domain and performance class of the
+// storage pool to the Ceph block pool failure domain and device class.
+func cephPoolPolicy(blockpool *StoragePool, profile *MappingProfile) (string, string, error) {
 	var domain string
 	var deviceClass string
 
+	if blockpool.Spec.DurabilityPolicy.FailureDomain == FailureDomainHost {
+		domain = "host"
+	} else if blockpool.Spec.DurabilityPolicy.FailureDomain == FailureDomainRack {
+		domain = "rack"
+	} else {
+		return "", "", &InvalidPolicyError{Field: "spec.durabilitypolicy.failuredomain",
+			Value: string(blockpool.Spec.DurabilityPolicy.FailureDomain), Reason: "one of host|rack"}
+	}
+	if perfClass := blockpool.Spec.PerfPolicy.IoPerfClass; len(perfClass) != 0 {
+		var ok bool
+		deviceClass, ok = profile.DeviceClasses[perfClass]
+		if !ok {
+			return "", "", &InvalidPolicyError{Field: "spec.perfpolicy.ioperfclass",
+				Value: string(perfClass), Reason: "not mapped to a device class"}
+		}
+	}
+	return domain, deviceClass, nil
+}
+
+// desiredCephBlockPool builds the Ceph block pool created for the storage
+// pool with the mappings of profile.
+func desiredCephBlockPool(blockpool *StoragePool, profile *MappingProfile) (*cephv1.CephBlockPool, error) {
+	var ret error
+
+	domain, deviceClass, err := cephPoolPolicy(blockpool, profile)
+	if err != nil {
+		return nil, err
+	}
+	pool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      blockpool.ObjectMeta.Name,
+			Namespace: blockpool.Spec.ClusterID,
+		},
+		Spec: cephv1.PoolSpec{
+			FailureDomain:   domain,
+			CrushRoot:       "",
+			CompressionMode: "none",
+			DeviceClass:     deviceClass,
+		},
+	}
+	if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassReplicated {
+		ret = setupReplicatedSpec(pool, blockpool, profile)
+		if ret != nil {
+			return nil, ret
+		}
+	} else if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassErasureCoded {
+		ret = setupErasureCodedSpec(pool, blockpool, profile)
+		if ret != nil {
+			return nil, ret
+		}
+	} else {
+		return nil, invalidDurabilityClass(blockpool)
+	}
+	ret = setupQuotas(pool, blockpool)
+	if ret != nil {
+		return nil, ret
+	}
+	setPoolOwner(pool, blockpool)
+	return pool, nil
+}
+
+// setPoolOwner records the owner of the storage pool on the Ceph block
+// pool, if any.
+func setPoolOwner(pool *cephv1.CephBlockPool, blockpool *StoragePool) {
+	owner, ok := blockpool.ObjectMeta.Annotations[PoolOwnerAnnotation]
+	if !ok {
+		return
+	}
+	if pool.ObjectMeta.Annotations == nil {
+		pool.ObjectMeta.Annotations = map[string]string{}
+	}
+	pool.ObjectMeta.Annotations[PoolOwnerAnnotation] = owner
+}
+
+// checkPoolOwner returns an *OwnerConflictError if the Ceph block pool is
+// owned by another storage pool than the owner of blockpool.
+func checkPoolOwner(pool *cephv1.CephBlockPool, blockpool *StoragePool) error {
+	owner := blockpool.ObjectMeta.Annotations[PoolOwnerAnnotation]
+	current := pool.ObjectMeta.Annotations[PoolOwnerAnnotation]
+	if len(owner) != 0 && len(current) != 0 && owner != current {
+		return &OwnerConflictError{Kind: "Ceph block pool", Name: pool.ObjectMeta.Name, Owner: current}
+	}
+	return nil
+}
+
+// profile returns the mapping profile of the client, the default one if
+// unset.
+func (p *StoragePools) profile() *MappingProfile {
+	if p.Profile == nil {
+		return defaultProfile
+	}
+	return p.Profile
+}
+
+// defaultedPool returns a copy of the storage pool with the defaults of
+// its spec set.
+func defaultedPool(blockpool *StoragePool) *StoragePool {
+	blockpool = blockpool.DeepCopy()
+	blockpool.Spec.SetDefaults()
+	return blockpool
+}
+
+func (p *StoragePools) CreateContext(ctx context.Context, blockpool *StoragePool) error {
+	blockpool = defaultedPool(blockpool)
 	poolname := blockpool.ObjectMeta.Name
 	clustername := blockpool.Spec.ClusterID
 	rookclnt := p.Client
-	_, err := rookclnt.CephV1().CephBlockPools(p.Namespace).Get(poolname, metav1.GetOptions{})
+	log := operationLogger(p.Log, "create", clustername, poolname)
+	err := validationError("StoragePool", poolname, blockpool.Spec.Validate(p.profile()))
+	if err != nil {
+		return err
+	}
+	_, err = rookclnt.CephV1().CephBlockPools(p.Namespace).Get(ctx, poolname, metav1.GetOptions{})
 	if err == nil {
-		ret = fmt.Errorf("Storage Pool already exists, cannot create blockpool")
-		return ret
+		return newAlreadyExists(cephv1.Resource("cephblockpools"), poolname)
+	} else if !apierrors.IsNotFound(err) {
+		return wrapError(err)
 	} else {
-		if blockpool.Spec.DurabilityPolicy.FailureDomain == FailureDomainHost {
-			domain = "host"
-		} else if blockpool.Spec.DurabilityPolicy.FailureDomain == FailureDomainRack {
-			domain = "rack"
-		} else {
-			ret = fmt.Errorf("Invalid Failure domain specified, failed to create storage pool")
+		pool, ret := desiredCephBlockPool(blockpool, p.profile())
+		if ret != nil {
 			return ret
 		}
-		if blockpool.Spec.PerfPolicy.IoPerfClass == DevStandard {
-			deviceClass = "hdd"
-		} else if blockpool.Spec.PerfPolicy.IoPerfClass == DevMedium {
-			deviceClass = "ssd"
-		} else if blockpool.Spec.PerfPolicy.IoPerfClass == DevFast {
-			deviceClass = "nvme"
-		}
-		pool := &cephv1.CephBlockPool{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      poolname,
-				Namespace: clustername,
-			},
-			Spec: cephv1.PoolSpec{
-				FailureDomain:   domain,
-				CrushRoot:       "",
-				CompressionMode: "none",
-				DeviceClass:     deviceClass,
-			},
-		}
-		if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassReplicated {
-			ret = setupReplicatedSpec(pool, blockpool)
-			if ret != nil {
-				return ret
-			}
-		} else if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassErasureCoded {
-			ret = setupErasureCodedSpec(pool, blockpool)
-			if ret != nil {
-				return ret
-			}
-		} else {
-			ret = fmt.Errorf("No valid durability class specified, failed to create storage pool")
-			return ret
-		}
-		_, err = rookclnt.CephV1().CephBlockPools(clustername).Create(pool)
+		_, err = rookclnt.CephV1().CephBlockPools(clustername).Create(ctx, pool, metav1.CreateOptions{})
 		if err == nil {
-			fmt.Printf("Ceph Block pool created %s \n", poolname)
+			log.Info("Ceph block pool created")
 		} else {
-			fmt.Printf("Failed to create Ceph block pool %v", err)
+			log.Error(err, "Failed to create Ceph block pool")
 		}
 	}
 
-	return err
+	return wrapError(err)
 }
 
-func (p *StoragePools) Update(blockpool *StoragePool) error {
-	var domain string
+// Create is CreateContext with a background context.
+func (p *StoragePools) Create(blockpool *StoragePool) error {
+	return p.CreateContext(context.Background(), blockpool)
+}
+
+// updateCephBlockPool applies the storage pool policies to the current
+// Ceph block pool with the mappings of profile, the durability class and
+// the owner cannot be changed.
+func updateCephBlockPool(pool *cephv1.CephBlockPool, blockpool *StoragePool, profile *MappingProfile) error {
+	if err := checkPoolOwner(pool, blockpool); err != nil {
+		return err
+	}
+	domain, deviceClass, err := cephPoolPolicy(blockpool, profile)
+	if err != nil {
+		return err
+	}
+	pool.Spec.DeviceClass = deviceClass
+	pool.Spec.FailureDomain = domain
+	durabilityClass := blockpool.Spec.DurabilityPolicy.DurabilityClass
+	current := DurabilityClassErasureCoded
+	if pool.Spec.Replicated.Size != 0 {
+		current = DurabilityClassReplicated
+	}
+	if durabilityClass == DurabilityClassReplicated || durabilityClass == DurabilityClassErasureCoded {
+		if durabilityClass != current {
+			return &UnsupportedTransitionError{Kind: "Ceph block pool", Name: pool.ObjectMeta.Name,
+				Field: "spec.durabilitypolicy.durabilityclass", From: current, To: durabilityClass}
+		}
+	}
+	if durabilityClass == DurabilityClassReplicated {
+		err = setupReplicatedSpec(pool, blockpool, profile)
+	} else if durabilityClass == DurabilityClassErasureCoded {
+		err = setupErasureCodedSpec(pool, blockpool, profile)
+	} else {
+		err = invalidDurabilityClass(blockpool)
+	}
+	if err != nil {
+		return err
+	}
+	setPoolOwner(pool, blockpool)
+	return setupQuotas(pool, blockpool)
+}
+
+func (p *StoragePools) UpdateContext(ctx context.Context, blockpool *StoragePool) error {
 	var ret error
-	var deviceClass string
 
+	blockpool = defaultedPool(blockpool)
 	rookclnt := p.Client
 	poolname := blockpool.ObjectMeta.Name
-	if blockpool.Spec.DurabilityPolicy.FailureDomain == FailureDomainHost {
-		domain = "host"
-	} else if blockpool.Spec.DurabilityPolicy.FailureDomain == FailureDomainRack {
-		domain = "rack"
-	} else {
-		ret = fmt.Errorf("Invalid Failure domain specified, failed to create storage pool")
+	log := operationLogger(p.Log, "update", p.Namespace, poolname)
+	ret = validationError("StoragePool", poolname, blockpool.Spec.Validate(p.profile()))
+	if ret != nil {
 		return ret
 	}
-	if blockpool.Spec.PerfPolicy.IoPerfClass == DevStandard {
-		deviceClass = "hdd"
-	} else if blockpool.Spec.PerfPolicy.IoPerfClass == DevMedium {
-		deviceClass = "ssd"
-	} else if blockpool.Spec.PerfPolicy.IoPerfClass == DevFast {
-		deviceClass = "nvme"
-	}
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		pool, err := rookclnt.CephV1().CephBlockPools(p.Namespace).Get(poolname, metav1.GetOptions{})
+		pool, err := rookclnt.CephV1().CephBlockPools(p.Namespace).Get(ctx, poolname, metav1.GetOptions{})
 		if err == nil {
-			pool.Spec.DeviceClass = deviceClass
-			pool.Spec.FailureDomain = domain
-			if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassReplicated && pool.Spec.Replicated.Size == 0 {
-				ret = fmt.Errorf("Failed to update Ceph block pool, Invalid durability class specified")
-				return ret
-			}
-			if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassErasureCoded && pool.Spec.Replicated.Size != 0 {
-				ret = fmt.Errorf("Failed to update Ceph block pool, Invalid durability class specified")
-				return ret
-			}
-			if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassReplicated {
-				ret = setupReplicatedSpec(pool, blockpool)
-				if ret != nil {
-					return ret
-				}
-			} else if blockpool.Spec.DurabilityPolicy.DurabilityClass == DurabilityClassErasureCoded {
-				ret = setupErasureCodedSpec(pool, blockpool)
-				if ret != nil {
-					return ret
-				}
-			} else {
-				ret = fmt.Errorf("No valid durability class specified, failed to create storage pool")
+			ret = updateCephBlockPool(pool, blockpool, p.profile())
+			if ret != nil {
 				return ret
 			}
-			_, err = rookclnt.CephV1().CephBlockPools(p.Namespace).Update(pool)
+			_, err = rookclnt.CephV1().CephBlockPools(p.Namespace).Update(ctx, pool, metav1.UpdateOptions{})
 			if err == nil {
-				fmt.Printf("Ceph Block pool updated %s \n", poolname)
+				log.Info("Ceph block pool updated")
 			}
 		}
 		return err
 	})
 
-	return err
+	return wrapError(err)
+}
+
+// Update is UpdateContext with a background context.
+func (p *StoragePools) Update(blockpool *StoragePool) error {
+	return p.UpdateContext(context.Background(), blockpool)
 }
 
 func mapPoolPhase(pool *cephv1.CephBlockPool) StoragePoolPhase {
-	if pool.Status.Phase == "Creating" {
+	if pool.Status == nil {
+		return ""
+	} else if pool.Status.Phase == "Creating" {
 		return PoolPhaseConnecting
 	} else if pool.Status.Phase == "Ready" {
 		return PoolPhaseReady
@@ -211,29 +341,21 @@ func mapPoolPhase(pool *cephv1.CephBlockPool) StoragePoolPhase {
 	return ""
 }
 
-func (p *StoragePools) Get(poolname string) (*StoragePool, error) {
+func (p *StoragePools) newStoragePool(pool *cephv1.CephBlockPool) *StoragePool {
 	var dPolicy StoragePolicyDurability
 	var phase StoragePoolPhase
 	var perfPolicy StoragePolicyPerformance
 
-	rookclnt := p.Client
-	pool, err := rookclnt.CephV1().CephBlockPools(p.Namespace).Get(poolname, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
-
+	profile := p.profile()
 	phase = mapPoolPhase(pool)
+	quota, quotaObjects := mapQuotas(pool)
 	perfPolicy.ObjectMeta.Namespace = "storage-config"
 	dPolicy.ObjectMeta.Namespace = "storage-config"
-	if pool.Spec.DeviceClass == "nvme" {
-		perfPolicy.ObjectMeta.Name = "sp-performance-fast"
-		perfPolicy.IoPerfClass = DevFast
-	} else if pool.Spec.DeviceClass == "ssd" {
-		perfPolicy.ObjectMeta.Name = "sp-performance-medium"
-		perfPolicy.IoPerfClass = DevMedium
-	} else if pool.Spec.DeviceClass == "hdd" {
+	perfPolicy.IoPerfClass = profile.devClass(pool.Spec.DeviceClass)
+	if perfPolicy.IoPerfClass == DevStandard {
 		perfPolicy.ObjectMeta.Name = "sp-performance-std"
-		perfPolicy.IoPerfClass = DevStandard
+	} else if len(perfPolicy.IoPerfClass) != 0 {
+		perfPolicy.ObjectMeta.Name = "sp-performance-" + string(perfPolicy.IoPerfClass)
 	}
 	if pool.Spec.FailureDomain == "host" {
 		dPolicy.FailureDomain = FailureDomainHost
@@ -242,37 +364,28 @@ func (p *StoragePools) Get(poolname string) (*StoragePool, error) {
 	}
 	if pool.Spec.Replicated.Size != 0 {
 		dPolicy.DurabilityClass = "replicated"
-		if pool.Spec.Replicated.Size == 1 {
-			dPolicy.ObjectMeta.Name = "sp-durability-low"
-			dPolicy.DurabilityLevel = DurabilityLevelLow
-		} else if pool.Spec.Replicated.Size == 2 {
-			dPolicy.ObjectMeta.Name = "sp-durability-semi"
-			dPolicy.DurabilityLevel = DurabilityLevelSemi
-		} else if pool.Spec.Replicated.Size == 3 {
-			dPolicy.ObjectMeta.Name = "sp-durability-normal"
-			dPolicy.DurabilityLevel = DurabilityLevelNormal
-		} else if pool.Spec.Replicated.Size == 4 {
-			dPolicy.ObjectMeta.Name = "sp-durability-high"
-			dPolicy.DurabilityLevel = DurabilityLevelHigh
+		dPolicy.DurabilityLevel = profile.replicatedLevel(pool.Spec.Replicated.Size)
+		if len(dPolicy.DurabilityLevel) != 0 {
+			dPolicy.ObjectMeta.Name = "sp-durability-" + string(dPolicy.DurabilityLevel)
 		}
 	} else {
 		dPolicy.DurabilityClass = "erasurecoded"
-		if pool.Spec.ErasureCoded.CodingChunks == 1 {
-			dPolicy.DurabilityLevel = DurabilityLevelSemi
-		} else if pool.Spec.ErasureCoded.CodingChunks == 2 {
-			dPolicy.DurabilityLevel = DurabilityLevelNormal
-		} else if pool.Spec.ErasureCoded.CodingChunks == 3 {
-			dPolicy.DurabilityLevel = DurabilityLevelHigh
-		}
+		dPolicy.DurabilityLevel = profile.erasureCodedLevel(ErasureCodedChunks{
+			DataChunks:   pool.Spec.ErasureCoded.DataChunks,
+			CodingChunks: pool.Spec.ErasureCoded.CodingChunks,
+		})
 	}
-	blockpool := &StoragePool{
+	return &StoragePool{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      poolname,
-			Namespace: p.Namespace,
+			Name:        pool.ObjectMeta.Name,
+			Namespace:   p.Namespace,
+			Labels:      pool.ObjectMeta.Labels,
+			Annotations: pool.ObjectMeta.Annotations,
 		},
 		Spec: StoragePoolSpec{
 			ClusterID:        pool.ObjectMeta.Namespace,
-			Quota:            0,
+			Quota:            quota,
+			QuotaObjects:     quotaObjects,
 			DurabilityPolicy: dPolicy,
 			PerfPolicy:       perfPolicy,
 		},
@@ -280,21 +393,72 @@ func (p *StoragePools) Get(poolname string) (*StoragePool, error) {
 			Phase: phase,
 		},
 	}
+}
 
-	return blockpool, nil
+func (p *StoragePools) GetContext(ctx context.Context, poolname string) (*StoragePool, error) {
+	rookclnt := p.Client
+	pool, err := rookclnt.CephV1().CephBlockPools(p.Namespace).Get(ctx, poolname, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return p.newStoragePool(pool), nil
 }
 
-func (p *StoragePools) Delete(poolname string) error {
+// Get is GetContext with a background context.
+func (p *StoragePools) Get(poolname string) (*StoragePool, error) {
+	return p.GetContext(context.Background(), poolname)
+}
+
+func (p *StoragePools) DeleteContext(ctx context.Context, poolname string) error {
 	rookclnt := p.Client
-	err := rookclnt.CephV1().CephBlockPools(p.Namespace).Delete(poolname, &metav1.DeleteOptions{})
+	err := rookclnt.CephV1().CephBlockPools(p.Namespace).Delete(ctx, poolname, metav1.DeleteOptions{})
 	if err == nil {
-		fmt.Printf("Ceph Block pool deleted %s \n", poolname)
+		operationLogger(p.Log, "delete", p.Namespace, poolname).Info("Ceph block pool deleted")
 	}
-	return err
+	return wrapError(err)
 }
 
-func (p *StoragePools) List() ([]StoragePool, error) {
-	var plist []StoragePool
+// Delete is DeleteContext with a background context.
+func (p *StoragePools) Delete(poolname string) error {
+	return p.DeleteContext(context.Background(), poolname)
+}
+
+// ListContext returns the storage pools in the namespace matching the label and
+// field selectors in opts. A non-nil filter further restricts the result to
+// pools whose durability and performance policies match it. The filter
+// runs on the client, so it cannot be combined with the paging of
+// opts.Limit and opts.Continue.
+func (p *StoragePools) ListContext(ctx context.Context, opts metav1.ListOptions, filter *StoragePoolFilter) (*StoragePoolList, error) {
+	if filter != nil && (opts.Limit != 0 || len(opts.Continue) != 0) {
+		return nil, apierrors.NewBadRequest("Failed to list storage pools: a filter cannot be combined with Limit or Continue")
+	}
+	rookclnt := p.Client
+	pools, err := rookclnt.CephV1().CephBlockPools(p.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	plist := &StoragePoolList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion:    pools.ListMeta.ResourceVersion,
+			Continue:           pools.ListMeta.Continue,
+			RemainingItemCount: pools.ListMeta.RemainingItemCount,
+		},
+		Items: make([]StoragePool, 0, len(pools.Items)),
+	}
+	for i := range pools.Items {
+		blockpool := p.newStoragePool(&pools.Items[i])
+		if filter != nil && !filter.Matches(blockpool) {
+			continue
+		}
+		plist.Items = append(plist.Items, *blockpool)
+	}
 
 	return plist, nil
 }
+
+// List is ListContext with a background context.
+func (p *StoragePools) List(opts metav1.ListOptions, filter *StoragePoolFilter) (*StoragePoolList, error) {
+	return p.ListContext(context.Background(), opts, filter)
+}
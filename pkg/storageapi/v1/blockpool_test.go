@@ -0,0 +1,312 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newReplicatedBlockPool(name, ns string, size uint, domain, deviceClass string) *cephv1.CephBlockPool {
+	return &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: cephv1.PoolSpec{
+			FailureDomain: domain,
+			DeviceClass:   deviceClass,
+			Replicated: cephv1.ReplicatedSpec{
+				Size: size,
+			},
+		},
+		Status: &cephv1.CephBlockPoolStatus{
+			Phase: "Ready",
+		},
+	}
+}
+
+func newErasureCodedBlockPool(name, ns string, dataChunks, codingChunks uint, domain, deviceClass string) *cephv1.CephBlockPool {
+	return &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: cephv1.PoolSpec{
+			FailureDomain: domain,
+			DeviceClass:   deviceClass,
+			ErasureCoded: cephv1.ErasureCodedSpec{
+				DataChunks:   dataChunks,
+				CodingChunks: codingChunks,
+			},
+		},
+		Status: &cephv1.CephBlockPoolStatus{
+			Phase: "Creating",
+		},
+	}
+}
+
+func TestStoragePoolsList(t *testing.T) {
+	objs := []runtime.Object{
+		newReplicatedBlockPool("rep-high-fast", "rook-ceph", 4, "host", "nvme"),
+		newReplicatedBlockPool("rep-normal-std", "rook-ceph", 3, "rack", "hdd"),
+		newReplicatedBlockPool("rep-high-medium", "rook-ceph", 4, "host", "ssd"),
+		newErasureCodedBlockPool("ec-high-fast", "rook-ceph", 4, 3, "host", "nvme"),
+		newReplicatedBlockPool("other-ns", "other", 4, "host", "nvme"),
+	}
+
+	tests := []struct {
+		name     string
+		filter   *StoragePoolFilter
+		expected []string
+	}{
+		{
+			name:     "no filter",
+			expected: []string{"ec-high-fast", "rep-high-fast", "rep-high-medium", "rep-normal-std"},
+		},
+		{
+			name: "replicated pools at high durability",
+			filter: &StoragePoolFilter{
+				DurabilityClass: DurabilityClassReplicated,
+				DurabilityLevel: DurabilityLevelHigh,
+			},
+			expected: []string{"rep-high-fast", "rep-high-medium"},
+		},
+		{
+			name:     "fast pools",
+			filter:   &StoragePoolFilter{IoPerfClass: DevFast},
+			expected: []string{"ec-high-fast", "rep-high-fast"},
+		},
+		{
+			name:     "rack failure domain",
+			filter:   &StoragePoolFilter{FailureDomain: FailureDomainRack},
+			expected: []string{"rep-normal-std"},
+		},
+		{
+			name: "erasure coded at semi durability",
+			filter: &StoragePoolFilter{
+				DurabilityClass: DurabilityClassErasureCoded,
+				DurabilityLevel: DurabilityLevelSemi,
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &StoragePools{
+				Namespace: "rook-ceph",
+				Client:    rookfake.NewSimpleClientset(objs...),
+			}
+			plist, err := p.List(metav1.ListOptions{}, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			names := []string{}
+			for _, pool := range plist.Items {
+				names = append(names, pool.Name)
+			}
+			sort.Strings(names)
+			if len(names) != len(tt.expected) {
+				t.Fatalf("expected pools %v, got %v", tt.expected, names)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Fatalf("expected pools %v, got %v", tt.expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestStoragePoolsListFilterPaging(t *testing.T) {
+	p := &StoragePools{Namespace: "rook-ceph", Client: rookfake.NewSimpleClientset()}
+	filter := &StoragePoolFilter{IoPerfClass: DevFast}
+
+	for _, opts := range []metav1.ListOptions{{Limit: 10}, {Continue: "page2"}} {
+		if _, err := p.List(opts, filter); !apierrors.IsBadRequest(err) {
+			t.Errorf("expected BadRequest for %+v, got %v", opts, err)
+		}
+	}
+	if _, err := p.List(metav1.ListOptions{Limit: 10}, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStoragePoolsListMatchesGet(t *testing.T) {
+	client := rookfake.NewSimpleClientset(
+		newErasureCodedBlockPool("ec-normal", "rook-ceph", 3, 2, "rack", "ssd"),
+	)
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	plist, err := p.List(metav1.ListOptions{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plist.Items) != 1 {
+		t.Fatalf("expected 1 pool, got %d", len(plist.Items))
+	}
+	pool, err := p.Get("ec-normal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listed := plist.Items[0]
+	if !reflect.DeepEqual(listed.Spec, pool.Spec) || !reflect.DeepEqual(listed.Status, pool.Status) {
+		t.Errorf("listed pool %+v does not match %+v", listed, *pool)
+	}
+	if listed.Spec.DurabilityPolicy.DurabilityLevel != DurabilityLevelNormal {
+		t.Errorf("expected durability level normal, got %s", listed.Spec.DurabilityPolicy.DurabilityLevel)
+	}
+	if listed.Status.Phase != PoolPhaseConnecting {
+		t.Errorf("expected phase Connecting, got %s", listed.Status.Phase)
+	}
+}
+
+func newQuotaPool(quota string, quotaObjects uint64) *StoragePool {
+	return &StoragePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "bpool1", Namespace: "rook-ceph"},
+		Spec: StoragePoolSpec{
+			ClusterID:    "rook-ceph",
+			Quota:        resource.MustParse(quota),
+			QuotaObjects: quotaObjects,
+			DurabilityPolicy: StoragePolicyDurability{
+				FailureDomain:   FailureDomainHost,
+				DurabilityClass: DurabilityClassReplicated,
+				DurabilityLevel: DurabilityLevelNormal,
+			},
+		},
+	}
+}
+
+func TestStoragePoolsQuota(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	if err := p.Create(newQuotaPool("500Gi", 1000)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, err := client.CephV1().CephBlockPools("rook-ceph").Get(context.TODO(), "bpool1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.Spec.Quotas.MaxSize == nil || *pool.Spec.Quotas.MaxSize != "500Gi" {
+		t.Errorf("expected max size 500Gi, got %v", pool.Spec.Quotas.MaxSize)
+	}
+	if pool.Spec.Quotas.MaxObjects == nil || *pool.Spec.Quotas.MaxObjects != 1000 {
+		t.Errorf("expected max objects 1000, got %v", pool.Spec.Quotas.MaxObjects)
+	}
+
+	tests := []struct {
+		name         string
+		quota        string
+		quotaObjects uint64
+		expectBytes  int64
+	}{
+		{name: "raw bytes", quota: "1073741824", quotaObjects: 10, expectBytes: 1 << 30},
+		{name: "human readable", quota: "2Ti", expectBytes: 2 << 40},
+		{name: "removed", quota: "0", expectBytes: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Update(newQuotaPool(tt.quota, tt.quotaObjects)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			blockpool, err := p.Get("bpool1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if blockpool.Spec.Quota.Value() != tt.expectBytes {
+				t.Errorf("expected quota %d, got %d", tt.expectBytes, blockpool.Spec.Quota.Value())
+			}
+			if blockpool.Spec.QuotaObjects != tt.quotaObjects {
+				t.Errorf("expected object quota %d, got %d", tt.quotaObjects, blockpool.Spec.QuotaObjects)
+			}
+		})
+	}
+}
+
+func TestStoragePoolsQuotaInvalid(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	if err := p.Create(newQuotaPool("-1Gi", 0)); err == nil {
+		t.Fatalf("expected error for negative quota")
+	}
+}
+
+func TestStoragePoolSpecQuotaJSON(t *testing.T) {
+	for _, raw := range []string{`{"quota": "500Gi"}`, `{"quota": 536870912000}`} {
+		spec := StoragePoolSpec{}
+		if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+			t.Fatalf("failed to decode %s: %v", raw, err)
+		}
+		if spec.Quota.Value() != 500<<30 {
+			t.Errorf("%s: expected %d bytes, got %d", raw, int64(500<<30), spec.Quota.Value())
+		}
+	}
+}
+
+func TestStoragePoolsDoneContext(t *testing.T) {
+	for name, ctx := range doneContexts() {
+		t.Run(name, func(t *testing.T) {
+			client := rookfake.NewSimpleClientset()
+			client.PrependReactor("*", "*", contextReactor(ctx))
+			p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+			if err := p.CreateContext(ctx, newQuotaPool("1Gi", 0)); !errors.Is(err, ctx.Err()) {
+				t.Errorf("expected %v from create, got %v", ctx.Err(), err)
+			}
+			if err := p.UpdateContext(ctx, newQuotaPool("2Gi", 0)); !errors.Is(err, ctx.Err()) {
+				t.Errorf("expected %v from update, got %v", ctx.Err(), err)
+			}
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "create" {
+					t.Errorf("expected no create once the context is done, got %v", action)
+				}
+			}
+			_, err := client.Tracker().Get(cephv1.SchemeGroupVersion.WithResource("cephblockpools"), "rook-ceph", "bpool1")
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("expected no Ceph block pool, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStoragePoolsOwner(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+	owned := func(owner string) *StoragePool {
+		blockpool := newQuotaPool("500Gi", 0)
+		blockpool.ObjectMeta.Annotations = map[string]string{PoolOwnerAnnotation: owner}
+		return blockpool
+	}
+
+	if err := p.Create(owned("apps/bpool1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, err := p.Get("bpool1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owner := pool.ObjectMeta.Annotations[PoolOwnerAnnotation]; owner != "apps/bpool1" {
+		t.Errorf("expected owner apps/bpool1, got %q", owner)
+	}
+	if err := p.Update(owned("team-a/bpool1")); !errors.Is(err, ErrOwnerConflict) {
+		t.Errorf("expected owner conflict, got %v", err)
+	}
+	if err := p.Update(owned("apps/bpool1")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := p.Update(newQuotaPool("1Ti", 0)); err != nil {
+		t.Errorf("unexpected error updating without an owner: %v", err)
+	}
+}
@@ -1,20 +1,31 @@
 package v1
 
 import (
-	"fmt"
+	"context"
+	"strings"
 
+	"github.com/go-logr/logr"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	rookv1 "github.com/rook/rook/pkg/apis/rook.io/v1"
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 type ClusterInterface interface {
 	Create(cluster *StorageCluster) (*StorageCluster, error)
 	Update(cluster *StorageCluster) (*StorageCluster, error)
 	Delete(clustername string) error
-	List() ([]StorageCluster, error)
+	List(opts metav1.ListOptions) (*StorageClusterList, error)
 	Get(clustername string) (*StorageCluster, error)
+	CreateContext(ctx context.Context, cluster *StorageCluster) (*StorageCluster, error)
+	UpdateContext(ctx context.Context, cluster *StorageCluster) (*StorageCluster, error)
+	DeleteContext(ctx context.Context, clustername string) error
+	ListContext(ctx context.Context, opts metav1.ListOptions) (*StorageClusterList, error)
+	GetContext(ctx context.Context, clustername string) (*StorageCluster, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (<-chan StorageClusterEvent, error)
 }
 
 const (
@@ -23,18 +34,21 @@ const (
 )
 
 type StorageClusters struct {
-	Namespace string
-	Client    *rookclient.Clientset
+	Namespace  string
+	Client     rookclient.Interface
+	KubeClient kubernetes.Interface
+	// Log receives the operations of the client, nil discards them.
+	Log logr.Logger
 }
 
-func (c *StorageClusters) Create(cluster *StorageCluster) (*StorageCluster, error) {
-	var external bool
+var _ ClusterInterface = &StorageClusters{}
 
-	rookclnt := c.Client
+// desiredCephCluster builds the Ceph cluster created for the storage cluster.
+func desiredCephCluster(cluster *StorageCluster) (*cephv1.CephCluster, error) {
 	useAllDevices := true
 	cephcluster := &cephv1.CephCluster{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: cluster.ObjectMeta.Name,
+			Name:      cluster.ObjectMeta.Name,
 			Namespace: cluster.ObjectMeta.Namespace,
 		},
 		Spec: cephv1.ClusterSpec{
@@ -44,46 +58,227 @@ func (c *StorageClusters) Create(cluster *StorageCluster) (*StorageCluster, erro
 				AllowMultiplePerNode: false,
 			},
 			Storage: rookv1.StorageScopeSpec{
-				UseAllNodes: true,
 				Selection: rookv1.Selection{
 					UseAllDevices: &useAllDevices,
 				},
 			},
 			Monitoring: cephv1.MonitoringSpec{
-				Enabled: true,
+				Enabled: cluster.Spec.Monitoring == nil || *cluster.Spec.Monitoring,
 			},
 		},
 	}
 	if len(cluster.Spec.StorageClusterID) != 0 {
-		cephcluster.Spec.External.Enable = external
+		err := validateExternalCluster(cluster)
+		if err != nil {
+			return nil, err
+		}
+		cephcluster.Spec.Storage = rookv1.StorageScopeSpec{}
+		cephcluster.Spec.Mon = cephv1.MonSpec{}
+		setupExternalSpec(cephcluster, cluster)
 	} else {
 		cephcluster.Spec.CephVersion.Image = cephversion
+		setupStorageNodes(cephcluster, cluster)
+	}
+	return cephcluster, nil
+}
+
+func (c *StorageClusters) CreateContext(ctx context.Context, cluster *StorageCluster) (*StorageCluster, error) {
+	rookclnt := c.Client
+	log := operationLogger(c.Log, "create", c.Namespace, cluster.ObjectMeta.Name)
+	err := validationError("StorageCluster", cluster.ObjectMeta.Name, cluster.Spec.Validate())
+	if err != nil {
+		return cluster, err
+	}
+	cephcluster, err := desiredCephCluster(cluster)
+	if err != nil {
+		return cluster, err
 	}
-	cephcluster, err := rookclnt.CephV1().CephClusters(c.Namespace).Create(cephcluster)
+	// The connection objects of an external cluster are shared by the
+	// namespace, they are only written for a Ceph cluster that does not
+	// exist yet and removed if it cannot be created.
+	_, err = rookclnt.CephV1().CephClusters(c.Namespace).Get(ctx, cluster.ObjectMeta.Name, metav1.GetOptions{})
 	if err == nil {
-		fmt.Printf("Ceph Cluster created %s \n", cluster.ObjectMeta.Name)
+		return cluster, newAlreadyExists(cephv1.Resource("cephclusters"), cluster.ObjectMeta.Name)
+	} else if !apierrors.IsNotFound(err) {
+		return cluster, wrapError(err)
 	}
+	external := len(cluster.Spec.StorageClusterID) != 0
+	if external {
+		err = createExternalConnection(ctx, c.KubeClient, c.Namespace, cluster)
+		if err != nil {
+			log.Error(err, "Failed to setup connection to external cluster")
+			return cluster, err
+		}
+	}
+	cephcluster, err = rookclnt.CephV1().CephClusters(c.Namespace).Create(ctx, cephcluster, metav1.CreateOptions{})
+	if err != nil {
+		if external {
+			configmap, secrets := externalConnection(c.Namespace, cluster)
+			deleteExternalConnection(ctx, c.KubeClient, c.Namespace, configmap, secrets)
+		}
+		return cluster, wrapError(err)
+	}
+	log.Info("Ceph cluster created")
 	cluster.Status.Phase = mapClusterPhase(cephcluster)
 	cluster.Status.State = mapClusterState(cephcluster)
 	cluster.Status.Message = cephcluster.Status.Message
 	return cluster, err
 }
 
+// Create is CreateContext with a background context.
+func (c *StorageClusters) Create(cluster *StorageCluster) (*StorageCluster, error) {
+	return c.CreateContext(context.Background(), cluster)
+}
+
+// newNodelist reverse maps the storage nodes of the Ceph cluster.
+func newNodelist(cephcluster *cephv1.CephCluster) []NodeInfo {
+	var nodelist []NodeInfo
+
+	if cephcluster.Spec.Storage.UseAllNodes == true {
+		return nil
+	}
+	for _, node := range cephcluster.Spec.Storage.Nodes {
+		nodeinfo := NodeInfo{
+			HostName: node.Name,
+		}
+		for _, device := range node.Selection.Devices {
+			if len(device.FullPath) != 0 {
+				nodeinfo.Devices = append(nodeinfo.Devices, device.FullPath)
+			} else {
+				nodeinfo.Devices = append(nodeinfo.Devices, device.Name)
+			}
+		}
+		nodelist = append(nodelist, nodeinfo)
+	}
+	return nodelist
+}
+
+// setupStorageNodes restricts the cluster storage to the nodes in the
+// node list, an empty list makes every node of the cluster available.
+func setupStorageNodes(cephcluster *cephv1.CephCluster, cluster *StorageCluster) {
+	if len(cluster.Spec.Nodelist) == 0 {
+		cephcluster.Spec.Storage.UseAllNodes = true
+		cephcluster.Spec.Storage.Nodes = nil
+		return
+	}
+
+	nodes := make([]rookv1.Node, 0, len(cluster.Spec.Nodelist))
+	for _, node := range cluster.Spec.Nodelist {
+		storagenode := rookv1.Node{
+			Name: node.HostName,
+		}
+		if len(node.Devices) != 0 {
+			useAllDevices := false
+			storagenode.Selection.UseAllDevices = &useAllDevices
+			for _, device := range node.Devices {
+				if strings.HasPrefix(device, "/") {
+					storagenode.Selection.Devices = append(storagenode.Selection.Devices, rookv1.Device{FullPath: device})
+				} else {
+					storagenode.Selection.Devices = append(storagenode.Selection.Devices, rookv1.Device{Name: device})
+				}
+			}
+		}
+		nodes = append(nodes, storagenode)
+	}
+	cephcluster.Spec.Storage.UseAllNodes = false
+	cephcluster.Spec.Storage.Nodes = nodes
+}
+
+// updateCephCluster applies the storage cluster spec to the current Ceph
+// cluster, refusing the changes that cannot be done in place.
+func updateCephCluster(current *cephv1.CephCluster, cluster *StorageCluster) error {
+	clustername := cluster.ObjectMeta.Name
+	external := len(cluster.Spec.StorageClusterID) != 0
+	if current.Spec.External.Enable != external {
+		modes := map[bool]string{false: "internal", true: "external"}
+		return &UnsupportedTransitionError{Kind: "Ceph cluster", Name: clustername, Field: "mode",
+			From: modes[current.Spec.External.Enable], To: modes[external]}
+	}
+	current.Spec.Monitoring.Enabled = cluster.Spec.Monitoring == nil || *cluster.Spec.Monitoring
+	if external == false {
+		setupStorageNodes(current, cluster)
+	} else if len(cluster.Spec.Nodelist) != 0 {
+		return &InvalidPolicyError{Field: "spec.nodelist", Reason: "cannot be set on an external cluster"}
+	} else if current.ObjectMeta.Annotations[storageClusterIDAnnotation] != cluster.Spec.StorageClusterID {
+		return &UnsupportedTransitionError{Kind: "Ceph cluster", Name: clustername, Field: "spec.storageclusterid",
+			From: current.ObjectMeta.Annotations[storageClusterIDAnnotation], To: cluster.Spec.StorageClusterID}
+	} else if cluster.Spec.External != nil {
+		return validateExternalCluster(cluster)
+	}
+	return nil
+}
+
+// UpdateContext applies the storage cluster spec to the existing Ceph cluster.
+// Switching a cluster between internal and external mode cannot be done
+// in place and is refused.
+func (c *StorageClusters) UpdateContext(ctx context.Context, cluster *StorageCluster) (*StorageCluster, error) {
+	var cephcluster *cephv1.CephCluster
+	var ret error
+
+	rookclnt := c.Client
+	clustername := cluster.ObjectMeta.Name
+	log := operationLogger(c.Log, "update", c.Namespace, clustername)
+	err := validationError("StorageCluster", clustername, cluster.Spec.Validate())
+	if err != nil {
+		return nil, err
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := rookclnt.CephV1().CephClusters(c.Namespace).Get(ctx, clustername, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		ret = updateCephCluster(current, cluster)
+		if ret != nil {
+			return ret
+		}
+		if current.Spec.External.Enable == true && cluster.Spec.External != nil {
+			ret = updateExternalConnection(ctx, c.KubeClient, c.Namespace, cluster)
+			if ret != nil {
+				return ret
+			}
+		}
+		cephcluster, err = rookclnt.CephV1().CephClusters(c.Namespace).Update(ctx, current, metav1.UpdateOptions{})
+		if err == nil {
+			log.Info("Ceph cluster updated")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return newStorageCluster(cephcluster), nil
+}
+
+// Update is UpdateContext with a background context.
 func (c *StorageClusters) Update(cluster *StorageCluster) (*StorageCluster, error) {
-	return nil, nil
+	return c.UpdateContext(context.Background(), cluster)
 }
 
-func (c *StorageClusters) Delete(clustername string) error {
+func (c *StorageClusters) DeleteContext(ctx context.Context, clustername string) error {
 	rookclnt := c.Client
-	err := rookclnt.CephV1().CephClusters(c.Namespace).Delete(clustername, &metav1.DeleteOptions{})
+	err := rookclnt.CephV1().CephClusters(c.Namespace).Delete(ctx, clustername, metav1.DeleteOptions{})
 	if err == nil {
-		fmt.Printf("Ceph cluster deleted %s \n", clustername)
+		operationLogger(c.Log, "delete", c.Namespace, clustername).Info("Ceph cluster deleted")
 	}
-	return err
+	return wrapError(err)
 }
 
+// Delete is DeleteContext with a background context.
+func (c *StorageClusters) Delete(clustername string) error {
+	return c.DeleteContext(context.Background(), clustername)
+}
+
+// Phases reported by older rook operators, no longer defined by the
+// rook API but still mapped.
+const (
+	cephConditionIgnored   cephv1.ConditionType = "Ignored"
+	cephConditionUpdating  cephv1.ConditionType = "Updating"
+	cephConditionUpgrading cephv1.ConditionType = "Upgrading"
+)
+
 func mapClusterPhase(c *cephv1.CephCluster) StorageClusterPhase {
-	if c.Status.Phase == cephv1.ConditionIgnored {
+	if c.Status.Phase == cephConditionIgnored {
 		return ClusterPhaseIgnored
 	} else if c.Status.Phase == cephv1.ConditionConnecting {
 		return ClusterPhaseConnecting
@@ -93,11 +288,11 @@ func mapClusterPhase(c *cephv1.CephCluster) StorageClusterPhase {
 		return ClusterPhaseProgressing
 	} else if c.Status.Phase == cephv1.ConditionReady {
 		return ClusterPhaseReady
-	} else if c.Status.Phase == cephv1.ConditionUpdating {
+	} else if c.Status.Phase == cephConditionUpdating {
 		return ClusterPhaseUpdating
 	} else if c.Status.Phase == cephv1.ConditionFailure {
 		return ClusterPhaseFailure
-	} else if c.Status.Phase == cephv1.ConditionUpgrading {
+	} else if c.Status.Phase == cephConditionUpgrading {
 		return ClusterPhaseUpgrading
 	} else if c.Status.Phase == cephv1.ConditionDeleting {
 		return ClusterPhaseDeleting
@@ -122,48 +317,79 @@ func mapClusterState(c *cephv1.CephCluster) StorageClusterState {
 	return ""
 }
 
-func (c *StorageClusters) Get(clustername string) (*StorageCluster, error) {
+func newStorageCluster(cephcluster *cephv1.CephCluster) *StorageCluster {
 	var monitoring bool
 	var externalClusterID string
-	var phase StorageClusterPhase
-	var state StorageClusterState
-
-	rookclnt := c.Client
-	cephcluster, err := rookclnt.CephV1().CephClusters(c.Namespace).Get(clustername, metav1.GetOptions{})
-	if err != nil {
-		return nil, err
-	}
 
 	if cephcluster.Spec.Monitoring.Enabled == true {
 		monitoring = true
 	}
 
 	if cephcluster.Spec.External.Enable == true {
-		externalClusterID = "external-storage-cluster"
+		externalClusterID = cephcluster.ObjectMeta.Annotations[storageClusterIDAnnotation]
 	}
 
-	phase = mapClusterPhase(cephcluster)
-	state = mapClusterState(cephcluster)
-	cluster := &StorageCluster{
+	return &StorageCluster{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: cephcluster.ObjectMeta.Name,
+			Name:      cephcluster.ObjectMeta.Name,
+			Namespace: cephcluster.ObjectMeta.Namespace,
+			Labels:    cephcluster.ObjectMeta.Labels,
 		},
 		Spec: StorageClusterSpec{
 			StorageClusterID: externalClusterID,
-			Monitoring:       monitoring,
+			Nodelist:         newNodelist(cephcluster),
+			Monitoring:       &monitoring,
 		},
 		Status: StorageClusterStatus{
-			Phase:   phase,
-			State:   state,
+			Phase:   mapClusterPhase(cephcluster),
+			State:   mapClusterState(cephcluster),
 			Message: cephcluster.Status.Message,
 		},
 	}
+}
+
+func (c *StorageClusters) GetContext(ctx context.Context, clustername string) (*StorageCluster, error) {
+	rookclnt := c.Client
+	cephcluster, err := rookclnt.CephV1().CephClusters(c.Namespace).Get(ctx, clustername, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
 
-	return cluster, nil
+	return newStorageCluster(cephcluster), nil
 }
 
-func (c *StorageClusters) List() ([]StorageCluster, error) {
-	var clist []StorageCluster
+// Get is GetContext with a background context.
+func (c *StorageClusters) Get(clustername string) (*StorageCluster, error) {
+	return c.GetContext(context.Background(), clustername)
+}
+
+// ListContext returns the storage clusters in the namespace matching the label
+// and field selectors in opts. If opts.Limit is set, only one page is
+// returned and the Continue token of the result can be passed back in
+// opts.Continue to fetch the next page.
+func (c *StorageClusters) ListContext(ctx context.Context, opts metav1.ListOptions) (*StorageClusterList, error) {
+	rookclnt := c.Client
+	cephclusters, err := rookclnt.CephV1().CephClusters(c.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	clist := &StorageClusterList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion:    cephclusters.ListMeta.ResourceVersion,
+			Continue:           cephclusters.ListMeta.Continue,
+			RemainingItemCount: cephclusters.ListMeta.RemainingItemCount,
+		},
+		Items: make([]StorageCluster, 0, len(cephclusters.Items)),
+	}
+	for i := range cephclusters.Items {
+		clist.Items = append(clist.Items, *newStorageCluster(&cephclusters.Items[i]))
+	}
 
 	return clist, nil
 }
+
+// List is ListContext with a background context.
+func (c *StorageClusters) List(opts metav1.ListOptions) (*StorageClusterList, error) {
+	return c.ListContext(context.Background(), opts)
+}
@@ -0,0 +1,521 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newCephCluster(name, ns string, labels map[string]string, phase cephv1.ConditionType, state cephv1.ClusterState) *cephv1.CephCluster {
+	return &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    labels,
+		},
+		Spec: cephv1.ClusterSpec{
+			Monitoring: cephv1.MonitoringSpec{
+				Enabled: true,
+			},
+		},
+		Status: cephv1.ClusterStatus{
+			Phase:   phase,
+			State:   state,
+			Message: "status of " + name,
+		},
+	}
+}
+
+func TestStorageClustersList(t *testing.T) {
+	objs := []runtime.Object{
+		newCephCluster("c1", "rook-ceph", map[string]string{"tier": "gold"}, cephv1.ConditionReady, cephv1.ClusterStateCreated),
+		newCephCluster("c2", "rook-ceph", map[string]string{"tier": "silver"}, cephv1.ConditionProgressing, cephv1.ClusterStateCreating),
+		newCephCluster("c3", "other", map[string]string{"tier": "gold"}, cephv1.ConditionFailure, cephv1.ClusterStateError),
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		opts      metav1.ListOptions
+		expected  map[string]StorageClusterStatus
+	}{
+		{
+			name:      "all clusters in namespace",
+			namespace: "rook-ceph",
+			expected: map[string]StorageClusterStatus{
+				"c1": {Phase: ClusterPhaseReady, State: ClusterStateCreated, Message: "status of c1"},
+				"c2": {Phase: ClusterPhaseProgressing, State: ClusterStateCreating, Message: "status of c2"},
+			},
+		},
+		{
+			name:      "label selector",
+			namespace: "rook-ceph",
+			opts:      metav1.ListOptions{LabelSelector: "tier=gold"},
+			expected: map[string]StorageClusterStatus{
+				"c1": {Phase: ClusterPhaseReady, State: ClusterStateCreated, Message: "status of c1"},
+			},
+		},
+		{
+			name:      "other namespace",
+			namespace: "other",
+			expected: map[string]StorageClusterStatus{
+				"c3": {Phase: ClusterPhaseFailure, State: ClusterStateError, Message: "status of c3"},
+			},
+		},
+		{
+			name:      "no match",
+			namespace: "rook-ceph",
+			opts:      metav1.ListOptions{LabelSelector: "tier=bronze"},
+			expected:  map[string]StorageClusterStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &StorageClusters{
+				Namespace: tt.namespace,
+				Client:    rookfake.NewSimpleClientset(objs...),
+			}
+			clist, err := c.List(tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(clist.Items) != len(tt.expected) {
+				t.Fatalf("expected %d clusters, got %d", len(tt.expected), len(clist.Items))
+			}
+			for _, cluster := range clist.Items {
+				status, ok := tt.expected[cluster.Name]
+				if !ok {
+					t.Fatalf("unexpected cluster %s", cluster.Name)
+				}
+				if cluster.Status != status {
+					t.Errorf("cluster %s: expected status %+v, got %+v", cluster.Name, status, cluster.Status)
+				}
+				if cluster.Namespace != tt.namespace {
+					t.Errorf("cluster %s: expected namespace %s, got %s", cluster.Name, tt.namespace, cluster.Namespace)
+				}
+				if cluster.Spec.Monitoring == nil || !*cluster.Spec.Monitoring {
+					t.Errorf("cluster %s: expected monitoring enabled", cluster.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageClustersListSelectorsPassedThrough(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	_, err := c.List(metav1.ListOptions{
+		LabelSelector: "tier=gold",
+		FieldSelector: "metadata.name=c1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actions := client.Actions()
+	if len(actions) != 1 {
+		t.Fatalf("expected 1 action, got %d", len(actions))
+	}
+	list, ok := actions[0].(k8stesting.ListAction)
+	if !ok {
+		t.Fatalf("expected list action, got %T", actions[0])
+	}
+	restrictions := list.GetListRestrictions()
+	if restrictions.Labels.String() != "tier=gold" {
+		t.Errorf("expected label selector tier=gold, got %s", restrictions.Labels.String())
+	}
+	if restrictions.Fields.String() != "metadata.name=c1" {
+		t.Errorf("expected field selector metadata.name=c1, got %s", restrictions.Fields.String())
+	}
+}
+
+func TestStorageClustersListPaginated(t *testing.T) {
+	pages := []*cephv1.CephClusterList{
+		{
+			ListMeta: metav1.ListMeta{Continue: "page2"},
+			Items:    []cephv1.CephCluster{*newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated)},
+		},
+		{
+			Items: []cephv1.CephCluster{*newCephCluster("c2", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated)},
+		},
+	}
+	client := rookfake.NewSimpleClientset()
+	// The fake list action does not record Limit/Continue, so pages are
+	// handed out in call order.
+	calls := 0
+	client.PrependReactor("list", "cephclusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if calls >= len(pages) {
+			return true, nil, fmt.Errorf("unexpected list call %d", calls)
+		}
+		page := pages[calls]
+		calls++
+		return true, page, nil
+	})
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	var names []string
+	opts := metav1.ListOptions{Limit: 1}
+	for {
+		clist, err := c.List(opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, cluster := range clist.Items {
+			names = append(names, cluster.Name)
+		}
+		if clist.Continue == "" {
+			break
+		}
+		if clist.Continue != "page2" {
+			t.Fatalf("expected continue token page2, got %q", clist.Continue)
+		}
+		opts.Continue = clist.Continue
+	}
+
+	if len(names) != 2 || names[0] != "c1" || names[1] != "c2" {
+		t.Errorf("expected [c1 c2], got %v", names)
+	}
+}
+
+func TestStorageClustersUpdate(t *testing.T) {
+	internal := newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated)
+	internal.Spec.Storage.UseAllNodes = true
+	external := newCephCluster("ext", "rook-ceph", nil, cephv1.ConditionConnected, cephv1.ClusterStateConnected)
+	external.Spec.External.Enable = true
+
+	tests := []struct {
+		name        string
+		spec        StorageClusterSpec
+		clustername string
+		expectErr   bool
+		expectNodes []string
+		expectAll   bool
+		monitoring  bool
+	}{
+		{
+			name:        "disable monitoring",
+			clustername: "c1",
+			spec:        StorageClusterSpec{Monitoring: newBool(false)},
+			expectAll:   true,
+			monitoring:  false,
+		},
+		{
+			name:        "restrict to node list",
+			clustername: "c1",
+			spec: StorageClusterSpec{
+				Monitoring: newBool(true),
+				Nodelist:   []NodeInfo{{HostName: "node-a"}, {HostName: "node-b"}},
+			},
+			expectNodes: []string{"node-a", "node-b"},
+			monitoring:  true,
+		},
+		{
+			name:        "internal to external refused",
+			clustername: "c1",
+			spec:        StorageClusterSpec{StorageClusterID: "remote"},
+			expectErr:   true,
+		},
+		{
+			name:        "external to internal refused",
+			clustername: "ext",
+			spec:        StorageClusterSpec{},
+			expectErr:   true,
+		},
+		{
+			name:        "node list on external refused",
+			clustername: "ext",
+			spec: StorageClusterSpec{
+				StorageClusterID: "remote",
+				Nodelist:         []NodeInfo{{HostName: "node-a"}},
+			},
+			expectErr: true,
+		},
+		{
+			name:        "missing cluster",
+			clustername: "missing",
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := rookfake.NewSimpleClientset(internal.DeepCopy(), external.DeepCopy())
+			c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+			cluster := &StorageCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.clustername, Namespace: "rook-ceph"},
+				Spec:       tt.spec,
+			}
+
+			updated, err := c.Update(cluster)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *updated.Spec.Monitoring != tt.monitoring {
+				t.Errorf("expected monitoring %v, got %v", tt.monitoring, *updated.Spec.Monitoring)
+			}
+
+			cephcluster, err := client.CephV1().CephClusters("rook-ceph").Get(context.TODO(), tt.clustername, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cephcluster.Spec.Storage.UseAllNodes != tt.expectAll {
+				t.Errorf("expected UseAllNodes %v, got %v", tt.expectAll, cephcluster.Spec.Storage.UseAllNodes)
+			}
+			if len(cephcluster.Spec.Storage.Nodes) != len(tt.expectNodes) {
+				t.Fatalf("expected nodes %v, got %v", tt.expectNodes, cephcluster.Spec.Storage.Nodes)
+			}
+			for i, node := range cephcluster.Spec.Storage.Nodes {
+				if node.Name != tt.expectNodes[i] {
+					t.Errorf("expected node %s, got %s", tt.expectNodes[i], node.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageClustersUpdateRetriesOnConflict(t *testing.T) {
+	client := rookfake.NewSimpleClientset(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+	conflicts := 0
+	client.PrependReactor("update", "cephclusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts == 0 {
+			conflicts++
+			return true, nil, apierrors.NewConflict(cephv1.Resource("cephclusters"), "c1", fmt.Errorf("stale"))
+		}
+		return false, nil, nil
+	})
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	cluster := &StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "rook-ceph"},
+		Spec:       StorageClusterSpec{Monitoring: newBool(false)},
+	}
+	if _, err := c.Update(cluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conflicts != 1 {
+		t.Errorf("expected one conflict, got %d", conflicts)
+	}
+	cephcluster, err := client.CephV1().CephClusters("rook-ceph").Get(context.TODO(), "c1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cephcluster.Spec.Monitoring.Enabled {
+		t.Errorf("expected monitoring disabled after retry")
+	}
+}
+
+func TestStorageClustersCreateNodelist(t *testing.T) {
+	tests := []struct {
+		name      string
+		nodelist  []NodeInfo
+		expectAll bool
+	}{
+		{
+			name:      "all nodes",
+			expectAll: true,
+		},
+		{
+			name: "dedicated nodes",
+			nodelist: []NodeInfo{
+				{HostName: "node-a", IPAddress: "10.0.0.1"},
+				{HostName: "node-b", Devices: []string{"sdb", "/dev/disk/by-id/nvme-1"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := rookfake.NewSimpleClientset()
+			c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+			cluster := &StorageCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "rook-ceph"},
+				Spec:       StorageClusterSpec{Nodelist: tt.nodelist},
+			}
+			if _, err := c.Create(cluster); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cephcluster, err := client.CephV1().CephClusters("rook-ceph").Get(context.TODO(), "c1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			storage := cephcluster.Spec.Storage
+			if storage.UseAllNodes != tt.expectAll {
+				t.Errorf("expected UseAllNodes %v, got %v", tt.expectAll, storage.UseAllNodes)
+			}
+			if len(storage.Nodes) != len(tt.nodelist) {
+				t.Fatalf("expected %d nodes, got %d", len(tt.nodelist), len(storage.Nodes))
+			}
+			for i, node := range storage.Nodes {
+				expected := tt.nodelist[i]
+				if node.Name != expected.HostName {
+					t.Errorf("expected node %s, got %s", expected.HostName, node.Name)
+				}
+				if len(node.Devices) != len(expected.Devices) {
+					t.Fatalf("node %s: expected devices %v, got %v", node.Name, expected.Devices, node.Devices)
+				}
+				if len(expected.Devices) != 0 && (node.UseAllDevices == nil || *node.UseAllDevices) {
+					t.Errorf("node %s: expected UseAllDevices false", node.Name)
+				}
+			}
+
+			got, err := c.Get("c1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got.Spec.Nodelist) != len(tt.nodelist) {
+				t.Fatalf("expected nodelist %v, got %v", tt.nodelist, got.Spec.Nodelist)
+			}
+			for i, node := range got.Spec.Nodelist {
+				expected := tt.nodelist[i]
+				if node.HostName != expected.HostName || !reflect.DeepEqual(node.Devices, expected.Devices) {
+					t.Errorf("expected node %+v, got %+v", expected, node)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageClustersMonitoring(t *testing.T) {
+	for _, monitoring := range []bool{true, false} {
+		t.Run(fmt.Sprintf("monitoring %v", monitoring), func(t *testing.T) {
+			client := rookfake.NewSimpleClientset()
+			c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+			cluster := &StorageCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "rook-ceph"},
+				Spec:       StorageClusterSpec{Monitoring: newBool(monitoring)},
+			}
+			if _, err := c.Create(cluster.DeepCopy()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cephcluster, err := client.CephV1().CephClusters("rook-ceph").Get(context.TODO(), "c1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cephcluster.Spec.Monitoring.Enabled != monitoring {
+				t.Errorf("expected monitoring %v after create, got %v", monitoring, cephcluster.Spec.Monitoring.Enabled)
+			}
+
+			// An update leaving the spec unchanged keeps monitoring as created.
+			if _, err := c.Update(cluster.DeepCopy()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := c.Get("c1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got.Spec.Monitoring != monitoring {
+				t.Errorf("expected monitoring %v after update, got %v", monitoring, *got.Spec.Monitoring)
+			}
+		})
+	}
+}
+
+func TestNodeInfoSerialization(t *testing.T) {
+	node := NodeInfo{HostName: "node-a", IPAddress: "10.0.0.1", Devices: []string{"sdb"}}
+
+	out, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"hostname":"node-a","ipaddress":"10.0.0.1","devices":["sdb"]}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+	decoded := NodeInfo{}
+	if err = json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, node) {
+		t.Errorf("expected %+v, got %+v", node, decoded)
+	}
+}
+
+func TestStorageClustersContext(t *testing.T) {
+	client := rookfake.NewSimpleClientset(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cluster, err := c.GetContext(ctx, "c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Status.Phase != ClusterPhaseReady {
+		t.Errorf("expected phase Ready, got %s", cluster.Status.Phase)
+	}
+	clist, err := c.ListContext(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clist.Items) != 1 {
+		t.Errorf("expected 1 cluster, got %d", len(clist.Items))
+	}
+	if err = c.DeleteContext(ctx, "c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = c.GetContext(ctx, "c1"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+}
+
+// doneContexts returns a cancelled context and a context past its deadline.
+func doneContexts() map[string]context.Context {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	cancel()
+	return map[string]context.Context{"cancelled": cancelled, "deadline exceeded": expired}
+}
+
+// contextReactor fails every request with the error of ctx, as the REST
+// clients do once the context of the request is done.
+func contextReactor(ctx context.Context) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, ctx.Err()
+	}
+}
+
+func TestStorageClustersDoneContext(t *testing.T) {
+	for name, ctx := range doneContexts() {
+		t.Run(name, func(t *testing.T) {
+			client := rookfake.NewSimpleClientset()
+			client.PrependReactor("*", "*", contextReactor(ctx))
+			c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+			cluster := &StorageCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "rook-ceph"},
+				Spec:       StorageClusterSpec{Monitoring: newBool(true)},
+			}
+
+			if _, err := c.CreateContext(ctx, cluster); !errors.Is(err, ctx.Err()) {
+				t.Errorf("expected %v from create, got %v", ctx.Err(), err)
+			}
+			if _, err := c.UpdateContext(ctx, cluster); !errors.Is(err, ctx.Err()) {
+				t.Errorf("expected %v from update, got %v", ctx.Err(), err)
+			}
+			_, err := client.Tracker().Get(cephv1.SchemeGroupVersion.WithResource("cephclusters"), "rook-ceph", "c1")
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("expected no Ceph cluster, got %v", err)
+			}
+		})
+	}
+}
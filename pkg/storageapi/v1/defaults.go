@@ -0,0 +1,40 @@
+package v1
+
+// Defaults of the spec fields documented on the types.
+const (
+	DefaultFailureDomain   FailureDomain   = FailureDomainHost
+	DefaultDurabilityClass DurabilityClass = DurabilityClassReplicated
+	DefaultDurabilityLevel DurabilityLevel = DurabilityLevelNormal
+	DefaultFSType          string          = "ext4"
+)
+
+// SetDefaults sets the unset durability policy fields of the storage pool
+// spec to their defaults.
+func (s *StoragePoolSpec) SetDefaults() {
+	durability := &s.DurabilityPolicy
+	if len(durability.FailureDomain) == 0 {
+		durability.FailureDomain = DefaultFailureDomain
+	}
+	if len(durability.DurabilityClass) == 0 {
+		durability.DurabilityClass = DefaultDurabilityClass
+	}
+	if len(durability.DurabilityLevel) == 0 {
+		durability.DurabilityLevel = DefaultDurabilityLevel
+	}
+}
+
+// SetDefaults sets the unset fields of the storage volume spec to their
+// defaults.
+func (s *StorageVolumeSpec) SetDefaults() {
+	if len(s.FSType) == 0 {
+		s.FSType = DefaultFSType
+	}
+	if s.Reclaim == nil {
+		reclaim := true
+		s.Reclaim = &reclaim
+	}
+	if s.AllowExpansion == nil {
+		allowExpansion := true
+		s.AllowExpansion = &allowExpansion
+	}
+}
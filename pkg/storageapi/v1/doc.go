@@ -0,0 +1,6 @@
+// Package v1 is the storage.rookclient.io/v1 API group, the storage
+// abstraction layer on top of rook Ceph.
+//
+// +groupName=storage.rookclient.io
+// +groupGoName=Storage
+package v1
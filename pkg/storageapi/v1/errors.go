@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Errors of the storage API, matched with errors.Is. Errors of the
+// Kubernetes API server are wrapped, apierrors.IsNotFound and errors.As
+// on *apierrors.StatusError keep working on them.
+var (
+	ErrAlreadyExists = errors.New("already exists")
+	ErrNotFound      = errors.New("not found")
+	// ErrInvalidPolicy is matched by every *InvalidPolicyError.
+	ErrInvalidPolicy = errors.New("invalid policy")
+	// ErrUnsupportedTransition is matched by every
+	// *UnsupportedTransitionError.
+	ErrUnsupportedTransition = errors.New("unsupported transition")
+	// ErrOwnerConflict is matched by every *OwnerConflictError.
+	ErrOwnerConflict = errors.New("owner conflict")
+)
+
+// apiError is an error of the Kubernetes API server matching the storage
+// API error it maps to.
+type apiError struct {
+	target error
+	err    error
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+func (e *apiError) Is(target error) bool {
+	return target == e.target
+}
+
+// wrapError maps the not found and already exists errors of the
+// Kubernetes API server to ErrNotFound and ErrAlreadyExists, other errors
+// are returned as is.
+func wrapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrAlreadyExists):
+		return err
+	case apierrors.IsNotFound(err):
+		return &apiError{target: ErrNotFound, err: err}
+	case apierrors.IsAlreadyExists(err):
+		return &apiError{target: ErrAlreadyExists, err: err}
+	}
+	return err
+}
+
+func newAlreadyExists(resource schema.GroupResource, name string) error {
+	return wrapError(apierrors.NewAlreadyExists(resource, name))
+}
+
+// InvalidPolicyError is returned when a field of the spec holds a value
+// the storage API cannot translate.
+type InvalidPolicyError struct {
+	// Field is the path of the offending field, e.g.
+	// "spec.durabilitypolicy.failuredomain".
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *InvalidPolicyError) Error() string {
+	if len(e.Value) == 0 {
+		return fmt.Sprintf("Invalid %s specified, %s", e.Field, e.Reason)
+	}
+	return fmt.Sprintf("Invalid %s %q specified, %s", e.Field, e.Value, e.Reason)
+}
+
+func (e *InvalidPolicyError) Is(target error) bool {
+	return target == ErrInvalidPolicy
+}
+
+// UnsupportedTransitionError is returned when an update changes a field
+// that cannot be changed in place.
+type UnsupportedTransitionError struct {
+	Kind  string
+	Name  string
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+func (e *UnsupportedTransitionError) Error() string {
+	return fmt.Sprintf("Failed to update %s %s, cannot change %s from %v to %v", e.Kind, e.Name, e.Field, e.From, e.To)
+}
+
+func (e *UnsupportedTransitionError) Is(target error) bool {
+	return target == ErrUnsupportedTransition
+}
+
+// OwnerConflictError is returned when an operation targets an object
+// created for another storage API object.
+type OwnerConflictError struct {
+	Kind string
+	Name string
+	// Owner is the namespace/name of the object owning it.
+	Owner string
+}
+
+func (e *OwnerConflictError) Error() string {
+	return fmt.Sprintf("Failed to sync %s %s, it is owned by %s", e.Kind, e.Name, e.Owner)
+}
+
+func (e *OwnerConflictError) Is(target error) bool {
+	return target == ErrOwnerConflict
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStorageAPIErrors(t *testing.T) {
+	rookclnt := rookfake.NewSimpleClientset()
+	pools := &StoragePools{Namespace: "rook-ceph", Client: rookclnt}
+	volumes := &StorageVolumes{Namespace: "rook-ceph", KubeClient: kubefake.NewSimpleClientset()}
+	if err := pools.Create(newQuotaPool("1Gi", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	erasureCoded := newQuotaPool("1Gi", 0)
+	erasureCoded.Spec.DurabilityPolicy.DurabilityClass = DurabilityClassErasureCoded
+	badDomain := newQuotaPool("1Gi", 0)
+	badDomain.Spec.DurabilityPolicy.FailureDomain = "zone"
+	lowErasureCoded := newQuotaPool("1Gi", 0)
+	lowErasureCoded.Name = "bpool2"
+	lowErasureCoded.Spec.DurabilityPolicy.DurabilityClass = DurabilityClassErasureCoded
+	lowErasureCoded.Spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelLow
+
+	tests := []struct {
+		name      string
+		call      func() error
+		target    error
+		field     string
+		apiStatus bool
+	}{
+		{
+			name:      "pool already exists",
+			call:      func() error { return pools.Create(newQuotaPool("1Gi", 0)) },
+			target:    ErrAlreadyExists,
+			apiStatus: true,
+		},
+		{
+			name:      "pool not found",
+			call:      func() error { _, err := pools.Get("bpool9"); return err },
+			target:    ErrNotFound,
+			apiStatus: true,
+		},
+		{
+			name:      "volume not found",
+			call:      func() error { return volumes.Delete("vol9") },
+			target:    ErrNotFound,
+			apiStatus: true,
+		},
+		{
+			name:      "unknown failure domain",
+			call:      func() error { return pools.Update(badDomain) },
+			target:    ErrInvalidPolicy,
+			field:     "spec.durabilitypolicy.failuredomain",
+			apiStatus: true,
+		},
+		{
+			name:      "low erasure coded pool",
+			call:      func() error { return pools.Create(lowErasureCoded) },
+			target:    ErrInvalidPolicy,
+			field:     "spec.durabilitypolicy.redundancylevel",
+			apiStatus: true,
+		},
+		{
+			name:   "invalid policy",
+			call:   func() error { return setupQuotas(&cephv1.CephBlockPool{}, newQuotaPool("-1Gi", 0)) },
+			target: ErrInvalidPolicy,
+			field:  "spec.quota",
+		},
+		{
+			name:   "durability class change",
+			call:   func() error { return pools.Update(erasureCoded) },
+			target: ErrUnsupportedTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.target) {
+				t.Fatalf("expected %v, got %v", tt.target, err)
+			}
+			var status *apierrors.StatusError
+			if errors.As(err, &status) != tt.apiStatus {
+				t.Errorf("expected wrapped API error %v, got %v", tt.apiStatus, err)
+			}
+			if tt.target == ErrNotFound && !apierrors.IsNotFound(err) {
+				t.Errorf("expected apierrors.IsNotFound, got %v", err)
+			}
+			if tt.target == ErrAlreadyExists && !apierrors.IsAlreadyExists(err) {
+				t.Errorf("expected apierrors.IsAlreadyExists, got %v", err)
+			}
+			var policy *InvalidPolicyError
+			if errors.As(err, &policy) && policy.Field != tt.field {
+				t.Errorf("expected field %s, got %s", tt.field, policy.Field)
+			}
+			if apierrors.IsInvalid(err) {
+				causes := status.Status().Details.Causes
+				if len(causes) != 1 || causes[0].Field != tt.field {
+					t.Errorf("expected a cause of field %s, got %+v", tt.field, causes)
+				}
+			}
+		})
+	}
+}
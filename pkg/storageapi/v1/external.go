@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Names of the secrets and configmap rook expects for an external cluster,
+// see rook's import-external-cluster.sh.
+const (
+	monSecretName           string = "rook-ceph-mon"
+	monEndpointsConfigMap   string = "rook-ceph-mon-endpoints"
+	csiRBDNodeSecret        string = "rook-csi-rbd-node"
+	csiRBDProvisionerSecret string = "rook-csi-rbd-provisioner"
+	csiRBDNodeUser          string = "csi-rbd-node"
+	csiRBDProvisionerUser   string = "csi-rbd-provisioner"
+
+	// Annotation recording the FSID of an external cluster on the CephCluster.
+	storageClusterIDAnnotation string = "storageapi.rookclient.io/storageclusterid"
+)
+
+func validateExternalCluster(cluster *StorageCluster) error {
+	external := cluster.Spec.External
+	reason := "required for external storage cluster " + cluster.ObjectMeta.Name
+	if external == nil {
+		return &InvalidPolicyError{Field: "spec.external", Reason: reason}
+	}
+	if len(external.MonEndpoints) == 0 {
+		return &InvalidPolicyError{Field: "spec.external.monendpoints", Reason: reason}
+	}
+	if len(external.AdminKey) == 0 {
+		return &InvalidPolicyError{Field: "spec.external.adminkey", Reason: reason}
+	}
+	return nil
+}
+
+// monEndpoints formats the endpoints the way rook stores them,
+// "a=10.0.0.1:6789,b=10.0.0.2:6789".
+func monEndpoints(endpoints []string) string {
+	mons := make([]string, 0, len(endpoints))
+	for i, endpoint := range endpoints {
+		mons = append(mons, string(rune('a'+i))+"="+endpoint)
+	}
+	return strings.Join(mons, ",")
+}
+
+func createOrUpdateSecret(ctx context.Context, kubeclnt kubernetes.Interface, secret *corev1.Secret) error {
+	secrets := kubeclnt.CoreV1().Secrets(secret.ObjectMeta.Namespace)
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+func createOrUpdateConfigMap(ctx context.Context, kubeclnt kubernetes.Interface, configmap *corev1.ConfigMap) error {
+	configmaps := kubeclnt.CoreV1().ConfigMaps(configmap.ObjectMeta.Namespace)
+	_, err := configmaps.Create(ctx, configmap, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = configmaps.Update(ctx, configmap, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// externalConnection returns the mon endpoints configmap and the mon and
+// CSI secrets rook needs to connect to the external cluster.
+func externalConnection(namespace string, cluster *StorageCluster) (*corev1.ConfigMap, []*corev1.Secret) {
+	external := cluster.Spec.External
+
+	configmap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      monEndpointsConfigMap,
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			"data":     monEndpoints(external.MonEndpoints),
+			"mapping":  `{"node":{}}`,
+			"maxMonId": strconv.Itoa(len(external.MonEndpoints) - 1),
+		},
+	}
+	secrets := []*corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      monSecretName,
+			Namespace: namespace,
+		},
+		StringData: map[string]string{
+			"cluster-name": namespace,
+			"fsid":         cluster.Spec.StorageClusterID,
+			"admin-secret": external.AdminKey,
+			"mon-secret":   "mon-secret",
+		},
+	}}
+	if len(external.CSIRBDNodeKey) != 0 {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      csiRBDNodeSecret,
+				Namespace: namespace,
+			},
+			StringData: map[string]string{
+				"userID":  csiRBDNodeUser,
+				"userKey": external.CSIRBDNodeKey,
+			},
+		})
+	}
+	if len(external.CSIRBDProvisionerKey) != 0 {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      csiRBDProvisionerSecret,
+				Namespace: namespace,
+			},
+			StringData: map[string]string{
+				"userID":  csiRBDProvisionerUser,
+				"userKey": external.CSIRBDProvisionerKey,
+			},
+		})
+	}
+	return configmap, secrets
+}
+
+// createExternalConnection creates the connection objects of a new
+// external cluster. Objects that already exist belong to another cluster
+// and are not overwritten, the objects created before a failure are
+// deleted.
+func createExternalConnection(ctx context.Context, kubeclnt kubernetes.Interface, namespace string, cluster *StorageCluster) error {
+	configmap, secrets := externalConnection(namespace, cluster)
+	_, err := kubeclnt.CoreV1().ConfigMaps(namespace).Create(ctx, configmap, metav1.CreateOptions{})
+	if err != nil {
+		return wrapError(err)
+	}
+	for i, secret := range secrets {
+		_, err = kubeclnt.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		if err != nil {
+			deleteExternalConnection(ctx, kubeclnt, namespace, configmap, secrets[:i])
+			return wrapError(err)
+		}
+	}
+	return nil
+}
+
+// deleteExternalConnection deletes the connection objects created by
+// createExternalConnection, on a best effort basis.
+func deleteExternalConnection(ctx context.Context, kubeclnt kubernetes.Interface, namespace string,
+	configmap *corev1.ConfigMap, secrets []*corev1.Secret) {
+	for _, secret := range secrets {
+		kubeclnt.CoreV1().Secrets(namespace).Delete(ctx, secret.ObjectMeta.Name, metav1.DeleteOptions{})
+	}
+	kubeclnt.CoreV1().ConfigMaps(namespace).Delete(ctx, configmap.ObjectMeta.Name, metav1.DeleteOptions{})
+}
+
+// updateExternalConnection applies the connection settings of an existing
+// external cluster to its connection objects.
+func updateExternalConnection(ctx context.Context, kubeclnt kubernetes.Interface, namespace string, cluster *StorageCluster) error {
+	configmap, secrets := externalConnection(namespace, cluster)
+	err := createOrUpdateConfigMap(ctx, kubeclnt, configmap)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		err = createOrUpdateSecret(ctx, kubeclnt, secret)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setupExternalSpec turns the Ceph cluster into a connection to the
+// external cluster, rook does not run any daemons for it.
+func setupExternalSpec(cephcluster *cephv1.CephCluster, cluster *StorageCluster) {
+	cephcluster.Spec.External.Enable = true
+	cephcluster.Spec.CrashCollector.Disable = true
+	if cephcluster.ObjectMeta.Annotations == nil {
+		cephcluster.ObjectMeta.Annotations = map[string]string{}
+	}
+	cephcluster.ObjectMeta.Annotations[storageClusterIDAnnotation] = cluster.Spec.StorageClusterID
+}
@@ -0,0 +1,245 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newExternalCluster(fsid string, endpoints ...string) *StorageCluster {
+	return &StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "ext", Namespace: "rook-ceph-external"},
+		Spec: StorageClusterSpec{
+			StorageClusterID: fsid,
+			External: &ExternalClusterSpec{
+				MonEndpoints:         endpoints,
+				AdminKey:             "admin-key",
+				CSIRBDNodeKey:        "node-key",
+				CSIRBDProvisionerKey: "provisioner-key",
+			},
+		},
+	}
+}
+
+func TestStorageClustersCreateExternal(t *testing.T) {
+	rookclnt := rookfake.NewSimpleClientset()
+	kubeclnt := kubefake.NewSimpleClientset()
+	c := &StorageClusters{Namespace: "rook-ceph-external", Client: rookclnt, KubeClient: kubeclnt}
+
+	fsid := "b2e8bd40-1d6a-4d5f-9e3c-3d1a5e1c6a01"
+	if _, err := c.Create(newExternalCluster(fsid, "10.0.0.1:6789", "10.0.0.2:6789")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cephcluster, err := rookclnt.CephV1().CephClusters("rook-ceph-external").Get(context.TODO(), "ext", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cephcluster.Spec.External.Enable {
+		t.Errorf("expected external mode enabled")
+	}
+	if len(cephcluster.Spec.CephVersion.Image) != 0 {
+		t.Errorf("expected no ceph image for external cluster, got %s", cephcluster.Spec.CephVersion.Image)
+	}
+
+	configmap, err := kubeclnt.CoreV1().ConfigMaps("rook-ceph-external").Get(context.TODO(), monEndpointsConfigMap, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("mon endpoints not created: %v", err)
+	}
+	if configmap.Data["data"] != "a=10.0.0.1:6789,b=10.0.0.2:6789" || configmap.Data["maxMonId"] != "1" {
+		t.Errorf("unexpected mon endpoints %v", configmap.Data)
+	}
+	secret, err := kubeclnt.CoreV1().Secrets("rook-ceph-external").Get(context.TODO(), monSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("mon secret not created: %v", err)
+	}
+	if secret.StringData["fsid"] != fsid || secret.StringData["admin-secret"] != "admin-key" {
+		t.Errorf("unexpected mon secret %v", secret.StringData)
+	}
+	for name, key := range map[string]string{csiRBDNodeSecret: "node-key", csiRBDProvisionerSecret: "provisioner-key"} {
+		secret, err = kubeclnt.CoreV1().Secrets("rook-ceph-external").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("secret %s not created: %v", name, err)
+		}
+		if secret.StringData["userKey"] != key {
+			t.Errorf("secret %s: expected key %s, got %s", name, key, secret.StringData["userKey"])
+		}
+	}
+
+	cluster, err := c.Get("ext")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Spec.StorageClusterID != fsid {
+		t.Errorf("expected cluster ID %s, got %s", fsid, cluster.Spec.StorageClusterID)
+	}
+}
+
+func TestStorageClustersCreateExternalInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster *StorageCluster
+	}{
+		{
+			name: "no connection settings",
+			cluster: &StorageCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "ext"},
+				Spec:       StorageClusterSpec{StorageClusterID: "fsid"},
+			},
+		},
+		{
+			name:    "no mon endpoints",
+			cluster: newExternalCluster("fsid"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rookclnt := rookfake.NewSimpleClientset()
+			c := &StorageClusters{Namespace: "rook-ceph-external", Client: rookclnt, KubeClient: kubefake.NewSimpleClientset()}
+			if _, err := c.Create(tt.cluster); err == nil {
+				t.Fatalf("expected error")
+			}
+			if len(rookclnt.Actions()) != 0 {
+				t.Errorf("expected no Ceph cluster calls, got %v", rookclnt.Actions())
+			}
+		})
+	}
+}
+
+func TestStorageClustersUpdateExternal(t *testing.T) {
+	rookclnt := rookfake.NewSimpleClientset()
+	kubeclnt := kubefake.NewSimpleClientset()
+	c := &StorageClusters{Namespace: "rook-ceph-external", Client: rookclnt, KubeClient: kubeclnt}
+	if _, err := c.Create(newExternalCluster("fsid-1", "10.0.0.1:6789")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := c.Update(newExternalCluster("fsid-2", "10.0.0.1:6789")); err == nil {
+		t.Errorf("expected error changing the external cluster ID")
+	}
+
+	if _, err := c.Update(newExternalCluster("fsid-1", "10.0.0.3:6789")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configmap, err := kubeclnt.CoreV1().ConfigMaps("rook-ceph-external").Get(context.TODO(), monEndpointsConfigMap, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if configmap.Data["data"] != "a=10.0.0.3:6789" {
+		t.Errorf("expected updated mon endpoints, got %s", configmap.Data["data"])
+	}
+}
+
+func TestStorageClustersCreateExternalDuplicate(t *testing.T) {
+	fsid := "b2e8bd40-1d6a-4d5f-9e3c-3d1a5e1c6a01"
+	tests := []struct {
+		name    string
+		cluster *StorageCluster
+	}{
+		{name: "same name", cluster: newExternalCluster("fsid-2", "10.0.0.9:6789")},
+		{
+			name: "other cluster in the namespace",
+			cluster: func() *StorageCluster {
+				cluster := newExternalCluster("fsid-2", "10.0.0.9:6789")
+				cluster.ObjectMeta.Name = "ext2"
+				return cluster
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rookclnt := rookfake.NewSimpleClientset()
+			kubeclnt := kubefake.NewSimpleClientset()
+			c := &StorageClusters{Namespace: "rook-ceph-external", Client: rookclnt, KubeClient: kubeclnt}
+			if _, err := c.Create(newExternalCluster(fsid, "10.0.0.1:6789")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err := c.Create(tt.cluster)
+			if !errors.Is(err, ErrAlreadyExists) {
+				t.Fatalf("expected already exists, got %v", err)
+			}
+			configmap, err := kubeclnt.CoreV1().ConfigMaps("rook-ceph-external").Get(context.TODO(), monEndpointsConfigMap, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if configmap.Data["data"] != "a=10.0.0.1:6789" {
+				t.Errorf("expected mon endpoints of the first cluster kept, got %s", configmap.Data["data"])
+			}
+			secret, err := kubeclnt.CoreV1().Secrets("rook-ceph-external").Get(context.TODO(), monSecretName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if secret.StringData["fsid"] != fsid {
+				t.Errorf("expected fsid of the first cluster kept, got %s", secret.StringData["fsid"])
+			}
+			if _, err := rookclnt.CephV1().CephClusters("rook-ceph-external").Get(context.TODO(), "ext2", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+				t.Errorf("expected no Ceph cluster ext2, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageClustersCreateExternalRollback(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(rookclnt *rookfake.Clientset, kubeclnt *kubefake.Clientset)
+		kept    []string
+	}{
+		{
+			name: "Ceph cluster create failure",
+			prepare: func(rookclnt *rookfake.Clientset, kubeclnt *kubefake.Clientset) {
+				rookclnt.PrependReactor("create", "cephclusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("connection refused")
+				})
+			},
+		},
+		{
+			name: "secret of another cluster",
+			prepare: func(rookclnt *rookfake.Clientset, kubeclnt *kubefake.Clientset) {
+				kubeclnt.Tracker().Add(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: csiRBDNodeSecret, Namespace: "rook-ceph-external"},
+				})
+			},
+			kept: []string{csiRBDNodeSecret},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rookclnt := rookfake.NewSimpleClientset()
+			kubeclnt := kubefake.NewSimpleClientset()
+			tt.prepare(rookclnt, kubeclnt)
+			c := &StorageClusters{Namespace: "rook-ceph-external", Client: rookclnt, KubeClient: kubeclnt}
+
+			if _, err := c.Create(newExternalCluster("fsid", "10.0.0.1:6789")); err == nil {
+				t.Fatalf("expected error")
+			}
+			if _, err := kubeclnt.CoreV1().ConfigMaps("rook-ceph-external").Get(context.TODO(), monEndpointsConfigMap, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+				t.Errorf("expected mon endpoints deleted, got %v", err)
+			}
+			secrets, err := kubeclnt.CoreV1().Secrets("rook-ceph-external").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, secret := range secrets.Items {
+				names = append(names, secret.ObjectMeta.Name)
+			}
+			if !reflect.DeepEqual(names, tt.kept) {
+				t.Errorf("expected secrets %v, got %v", tt.kept, names)
+			}
+		})
+	}
+}
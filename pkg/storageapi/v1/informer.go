@@ -0,0 +1,254 @@
+package v1
+
+import (
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	rookinformers "github.com/rook/rook/pkg/client/informers/externalversions"
+	cephinformers "github.com/rook/rook/pkg/client/informers/externalversions/ceph.rook.io/v1"
+	cephlisters "github.com/rook/rook/pkg/client/listers/ceph.rook.io/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SharedInformerFactory runs the rook shared informers backing the storage
+// clusters and storage pools of a namespace.
+type SharedInformerFactory struct {
+	Namespace string
+	// Profile reverse maps the Ceph block pools, nil uses
+	// DefaultMappingProfile.
+	Profile *MappingProfile
+	factory rookinformers.SharedInformerFactory
+}
+
+// NewSharedInformerFactory returns an informer factory for namespace, the
+// cached objects are resynced every resync, zero disables resyncs.
+func NewSharedInformerFactory(client rookclient.Interface, namespace string, resync time.Duration) *SharedInformerFactory {
+	return &SharedInformerFactory{
+		Namespace: namespace,
+		factory:   rookinformers.NewSharedInformerFactoryWithOptions(client, resync, rookinformers.WithNamespace(namespace)),
+	}
+}
+
+// Start starts the informers requested so far, they run until stopCh is closed.
+func (f *SharedInformerFactory) Start(stopCh <-chan struct{}) {
+	f.factory.Start(stopCh)
+}
+
+// WaitForCacheSync waits until the caches of the started informers are
+// synced, it returns false if stopCh was closed first.
+func (f *SharedInformerFactory) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	for _, synced := range f.factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *SharedInformerFactory) StorageClusters() *StorageClusterInformer {
+	return &StorageClusterInformer{
+		namespace: f.Namespace,
+		informer:  f.factory.Ceph().V1().CephClusters(),
+	}
+}
+
+func (f *SharedInformerFactory) StoragePools() *StoragePoolInformer {
+	return &StoragePoolInformer{
+		pools:    &StoragePools{Namespace: f.Namespace, Profile: f.Profile},
+		informer: f.factory.Ceph().V1().CephBlockPools(),
+	}
+}
+
+// StorageClusterEventHandlerFuncs are called with the translated storage
+// clusters, a nil func ignores the event.
+type StorageClusterEventHandlerFuncs struct {
+	AddFunc    func(cluster *StorageCluster)
+	UpdateFunc func(oldCluster, newCluster *StorageCluster)
+	DeleteFunc func(cluster *StorageCluster)
+}
+
+// StoragePoolEventHandlerFuncs are called with the translated storage
+// pools, a nil func ignores the event.
+type StoragePoolEventHandlerFuncs struct {
+	AddFunc    func(blockpool *StoragePool)
+	UpdateFunc func(oldPool, newPool *StoragePool)
+	DeleteFunc func(blockpool *StoragePool)
+}
+
+// deletedObject unwraps the final state of objects whose delete was
+// missed by the informer.
+func deletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+type StorageClusterInformer struct {
+	namespace string
+	informer  cephinformers.CephClusterInformer
+}
+
+// Informer returns the underlying CephCluster informer.
+func (i *StorageClusterInformer) Informer() cache.SharedIndexInformer {
+	return i.informer.Informer()
+}
+
+func (i *StorageClusterInformer) Lister() StorageClusterLister {
+	return &storageClusterLister{
+		namespace: i.namespace,
+		indexer:   i.informer.Informer().GetIndexer(),
+	}
+}
+
+// AddEventHandler registers handler, UpdateFunc is also called on every
+// resync with identical objects.
+func (i *StorageClusterInformer) AddEventHandler(handler StorageClusterEventHandlerFuncs) {
+	i.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cephcluster, ok := obj.(*cephv1.CephCluster)
+			if ok && handler.AddFunc != nil {
+				handler.AddFunc(newStorageCluster(cephcluster))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldCluster, ok := oldObj.(*cephv1.CephCluster)
+			if !ok || handler.UpdateFunc == nil {
+				return
+			}
+			newCluster, ok := newObj.(*cephv1.CephCluster)
+			if ok {
+				handler.UpdateFunc(newStorageCluster(oldCluster), newStorageCluster(newCluster))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			cephcluster, ok := deletedObject(obj).(*cephv1.CephCluster)
+			if ok && handler.DeleteFunc != nil {
+				handler.DeleteFunc(newStorageCluster(cephcluster))
+			}
+		},
+	})
+}
+
+type StoragePoolInformer struct {
+	pools    *StoragePools
+	informer cephinformers.CephBlockPoolInformer
+}
+
+// Informer returns the underlying CephBlockPool informer.
+func (i *StoragePoolInformer) Informer() cache.SharedIndexInformer {
+	return i.informer.Informer()
+}
+
+// Profile returns the mapping profile the storage pools are reverse mapped
+// with.
+func (i *StoragePoolInformer) Profile() *MappingProfile {
+	return i.pools.profile()
+}
+
+func (i *StoragePoolInformer) Lister() StoragePoolLister {
+	return &storagePoolLister{
+		pools:   i.pools,
+		indexer: i.informer.Informer().GetIndexer(),
+	}
+}
+
+// AddEventHandler registers handler, UpdateFunc is also called on every
+// resync with identical objects.
+func (i *StoragePoolInformer) AddEventHandler(handler StoragePoolEventHandlerFuncs) {
+	i.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			pool, ok := obj.(*cephv1.CephBlockPool)
+			if ok && handler.AddFunc != nil {
+				handler.AddFunc(i.pools.newStoragePool(pool))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPool, ok := oldObj.(*cephv1.CephBlockPool)
+			if !ok || handler.UpdateFunc == nil {
+				return
+			}
+			newPool, ok := newObj.(*cephv1.CephBlockPool)
+			if ok {
+				handler.UpdateFunc(i.pools.newStoragePool(oldPool), i.pools.newStoragePool(newPool))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			pool, ok := deletedObject(obj).(*cephv1.CephBlockPool)
+			if ok && handler.DeleteFunc != nil {
+				handler.DeleteFunc(i.pools.newStoragePool(pool))
+			}
+		},
+	})
+}
+
+// StorageClusterLister lists storage clusters from the informer cache.
+type StorageClusterLister interface {
+	List(selector labels.Selector) ([]*StorageCluster, error)
+	Get(clustername string) (*StorageCluster, error)
+}
+
+// StoragePoolLister lists storage pools from the informer cache, a
+// non-nil filter restricts the list to the matching pools.
+type StoragePoolLister interface {
+	List(selector labels.Selector, filter *StoragePoolFilter) ([]*StoragePool, error)
+	Get(poolname string) (*StoragePool, error)
+}
+
+type storageClusterLister struct {
+	namespace string
+	indexer   cache.Indexer
+}
+
+func (l *storageClusterLister) List(selector labels.Selector) ([]*StorageCluster, error) {
+	var clusters []*StorageCluster
+
+	cephclusters, err := cephlisters.NewCephClusterLister(l.indexer).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, cephcluster := range cephclusters {
+		clusters = append(clusters, newStorageCluster(cephcluster))
+	}
+	return clusters, nil
+}
+
+func (l *storageClusterLister) Get(clustername string) (*StorageCluster, error) {
+	cephcluster, err := cephlisters.NewCephClusterLister(l.indexer).CephClusters(l.namespace).Get(clustername)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return newStorageCluster(cephcluster), nil
+}
+
+type storagePoolLister struct {
+	pools   *StoragePools
+	indexer cache.Indexer
+}
+
+func (l *storagePoolLister) List(selector labels.Selector, filter *StoragePoolFilter) ([]*StoragePool, error) {
+	var blockpools []*StoragePool
+
+	pools, err := cephlisters.NewCephBlockPoolLister(l.indexer).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		blockpool := l.pools.newStoragePool(pool)
+		if filter != nil && !filter.Matches(blockpool) {
+			continue
+		}
+		blockpools = append(blockpools, blockpool)
+	}
+	return blockpools, nil
+}
+
+func (l *storagePoolLister) Get(poolname string) (*StoragePool, error) {
+	pool, err := cephlisters.NewCephBlockPoolLister(l.indexer).CephBlockPools(l.pools.Namespace).Get(poolname)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return l.pools.newStoragePool(pool), nil
+}
@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestStorageClusterLister(t *testing.T) {
+	client := rookfake.NewSimpleClientset(
+		newCephCluster("c1", "rook-ceph", map[string]string{"tier": "gold"}, cephv1.ConditionReady, cephv1.ClusterStateCreated),
+		newCephCluster("c2", "rook-ceph", nil, cephv1.ConditionProgressing, cephv1.ClusterStateCreating),
+		newCephCluster("c3", "other", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated),
+	)
+	factory := NewSharedInformerFactory(client, "rook-ceph", 0)
+	lister := factory.StorageClusters().Lister()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	if !factory.WaitForCacheSync(stopCh) {
+		t.Fatalf("cache not synced")
+	}
+
+	cluster, err := lister.Get("c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Status.Phase != ClusterPhaseReady || cluster.Status.State != ClusterStateCreated {
+		t.Errorf("unexpected phase %s state %s", cluster.Status.Phase, cluster.Status.State)
+	}
+	if _, err := lister.Get("c3"); !apierrors.IsNotFound(err) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found for cluster of another namespace, got %v", err)
+	}
+
+	clusters, err := lister.List(labels.Everything())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Errorf("expected 2 clusters, got %d", len(clusters))
+	}
+	clusters, err = lister.List(labels.SelectorFromSet(labels.Set{"tier": "gold"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "c1" {
+		t.Errorf("expected only c1, got %v", clusters)
+	}
+
+	actions := len(client.Actions())
+	if _, err := lister.Get("c2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.Actions()) != actions {
+		t.Errorf("expected lister served from cache, got %v", client.Actions()[actions:])
+	}
+}
+
+func TestStoragePoolListerFilter(t *testing.T) {
+	client := rookfake.NewSimpleClientset(
+		newReplicatedBlockPool("rpool", "rook-ceph", 3, "host", "ssd"),
+		newErasureCodedBlockPool("ecpool", "rook-ceph", 3, 2, "rack", "hdd"),
+	)
+	factory := NewSharedInformerFactory(client, "rook-ceph", 0)
+	lister := factory.StoragePools().Lister()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	if !factory.WaitForCacheSync(stopCh) {
+		t.Fatalf("cache not synced")
+	}
+
+	pools, err := lister.List(labels.Everything(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pools) != 2 {
+		t.Errorf("expected 2 pools, got %d", len(pools))
+	}
+	pools, err = lister.List(labels.Everything(), &StoragePoolFilter{DurabilityClass: DurabilityClassErasureCoded})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pools) != 1 || pools[0].Name != "ecpool" {
+		t.Errorf("expected only ecpool, got %v", pools)
+	}
+
+	blockpool, err := lister.Get("rpool")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if blockpool.Spec.DurabilityPolicy.DurabilityLevel != DurabilityLevelNormal || blockpool.Spec.PerfPolicy.IoPerfClass != DevMedium {
+		t.Errorf("unexpected pool spec %+v", blockpool.Spec)
+	}
+	if _, err := lister.Get("missing"); !apierrors.IsNotFound(err) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found for a missing pool, got %v", err)
+	}
+}
+
+func TestStoragePoolInformerEventHandler(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	factory := NewSharedInformerFactory(client, "rook-ceph", 0)
+
+	added := make(chan *StoragePool, 1)
+	deleted := make(chan *StoragePool, 1)
+	factory.StoragePools().AddEventHandler(StoragePoolEventHandlerFuncs{
+		AddFunc: func(blockpool *StoragePool) {
+			added <- blockpool
+		},
+		DeleteFunc: func(blockpool *StoragePool) {
+			deleted <- blockpool
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	if !factory.WaitForCacheSync(stopCh) {
+		t.Fatalf("cache not synced")
+	}
+
+	pools := client.CephV1().CephBlockPools("rook-ceph")
+	_, err := pools.Create(context.TODO(), newReplicatedBlockPool("bpool1", "rook-ceph", 2, "host", "nvme"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case blockpool := <-added:
+		if blockpool.Name != "bpool1" || blockpool.Spec.DurabilityPolicy.DurabilityLevel != DurabilityLevelSemi {
+			t.Errorf("unexpected added pool %+v", blockpool)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("no add event")
+	}
+
+	if err := pools.Delete(context.TODO(), "bpool1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case blockpool := <-deleted:
+		if blockpool.Name != "bpool1" {
+			t.Errorf("unexpected deleted pool %s", blockpool.Name)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("no delete event")
+	}
+}
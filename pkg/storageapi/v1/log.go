@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// Keys of the structured log values of the storage API clients.
+const (
+	logKeyOperation string = "operation"
+	logKeyNamespace string = "namespace"
+	logKeyName      string = "name"
+)
+
+// operationLogger returns log with the operation, namespace and name of the
+// object, a nil log discards the messages.
+func operationLogger(log logr.Logger, operation string, namespace string, name string) logr.Logger {
+	if log == nil {
+		return logr.Discard()
+	}
+	return log.WithValues(logKeyOperation, operation, logKeyNamespace, namespace, logKeyName, name)
+}
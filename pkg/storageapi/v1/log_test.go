@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// recordingLogger records the messages logged with their key/values.
+type recordingLogger struct {
+	values  []interface{}
+	entries *[]logEntry
+}
+
+type logEntry struct {
+	msg    string
+	err    error
+	values map[string]interface{}
+}
+
+func (l recordingLogger) record(msg string, err error, keysAndValues []interface{}) {
+	values := map[string]interface{}{}
+	all := append(append([]interface{}{}, l.values...), keysAndValues...)
+	for i := 0; i+1 < len(all); i += 2 {
+		values[all[i].(string)] = all[i+1]
+	}
+	*l.entries = append(*l.entries, logEntry{msg: msg, err: err, values: values})
+}
+
+func (l recordingLogger) Enabled() bool { return true }
+
+func (l recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.record(msg, nil, keysAndValues)
+}
+
+func (l recordingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.record(msg, err, keysAndValues)
+}
+
+func (l recordingLogger) V(level int) logr.Logger { return l }
+
+func (l recordingLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return recordingLogger{values: append(append([]interface{}{}, l.values...), keysAndValues...), entries: l.entries}
+}
+
+func (l recordingLogger) WithName(name string) logr.Logger { return l }
+
+func TestClientLogging(t *testing.T) {
+	var entries []logEntry
+	log := recordingLogger{entries: &entries}
+	pools := &StoragePools{Namespace: "rook-ceph", Client: rookfake.NewSimpleClientset(), Log: log}
+	volumes := &StorageVolumes{Namespace: "rook-ceph", KubeClient: kubefake.NewSimpleClientset(), Log: log}
+
+	if err := pools.Create(newQuotaPool("1Gi", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := pools.Update(newQuotaPool("2Gi", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := volumes.Create(newBlockVolume("vol1", true)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := volumes.Delete("vol1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		msg       string
+		operation string
+		name      string
+	}{
+		{msg: "Ceph block pool created", operation: "create", name: "bpool1"},
+		{msg: "Ceph block pool updated", operation: "update", name: "bpool1"},
+		{msg: "Storage class created", operation: "create", name: "vol1"},
+		{msg: "Storage class deleted", operation: "delete", name: "vol1"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d log entries, got %+v", len(expected), entries)
+	}
+	for i, e := range expected {
+		entry := entries[i]
+		if entry.msg != e.msg || entry.values[logKeyOperation] != e.operation ||
+			entry.values[logKeyNamespace] != "rook-ceph" || entry.values[logKeyName] != e.name {
+			t.Errorf("expected %+v, got %+v", e, entry)
+		}
+	}
+	if entries[2].values["storageclass"] != "rook-ceph.vol1-block" {
+		t.Errorf("expected the storage class name, got %+v", entries[2])
+	}
+}
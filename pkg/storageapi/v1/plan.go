@@ -0,0 +1,269 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// PlanAction is the change a plan would make to the Ceph object.
+type PlanAction string
+
+const (
+	PlanActionCreate  PlanAction = "create"
+	PlanActionUpdate  PlanAction = "update"
+	PlanActionReplace PlanAction = "replace"
+	PlanActionNone    PlanAction = "none"
+)
+
+// Plan is the dry run of a Create or Update, it holds the CephCluster,
+// CephBlockPool or StorageClass that would be sent and its line diff
+// against the existing object.
+type Plan struct {
+	Action    PlanAction
+	Kind      string
+	Namespace string
+	Name      string
+	// Current is the existing object, nil when it does not exist.
+	Current runtime.Object
+	Desired runtime.Object
+	Diff    string
+}
+
+// planObject reduces the object to the fields set by the storage API,
+// dropping the status and the metadata maintained by the API server.
+func planObject(obj runtime.Object) (map[string]interface{}, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for key := range metadata {
+			switch key {
+			case "name", "namespace", "labels", "annotations":
+			default:
+				delete(metadata, key)
+			}
+		}
+	}
+	return content, nil
+}
+
+func planLines(obj runtime.Object) ([]string, error) {
+	content, err := planObject(obj)
+	if err != nil || content == nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"), nil
+}
+
+// diffLines returns the line diff of a and b from their longest common
+// subsequence, removed lines are prefixed with "-" and added ones with "+".
+func diffLines(a []string, b []string) (string, bool) {
+	var diff strings.Builder
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	changed := false
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			fmt.Fprintf(&diff, "  %s\n", a[i])
+			i++
+			j++
+		} else if j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]) {
+			fmt.Fprintf(&diff, "- %s\n", a[i])
+			changed = true
+			i++
+		} else {
+			fmt.Fprintf(&diff, "+ %s\n", b[j])
+			changed = true
+			j++
+		}
+	}
+	return diff.String(), changed
+}
+
+// newPlan diffs the desired object against the current one, an unchanged
+// object is planned with PlanActionNone.
+func newPlan(action PlanAction, kind string, namespace string, name string, current runtime.Object, desired runtime.Object) (*Plan, error) {
+	a, err := planLines(current)
+	if err != nil {
+		return nil, err
+	}
+	b, err := planLines(desired)
+	if err != nil {
+		return nil, err
+	}
+	diff, changed := diffLines(a, b)
+	if !changed {
+		action = PlanActionNone
+	}
+	return &Plan{
+		Action:    action,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Current:   current,
+		Desired:   desired,
+		Diff:      diff,
+	}, nil
+}
+
+// PlanCreate returns the Ceph cluster Create would send without creating
+// it. If the Ceph cluster already exists, the plan diffs it against the
+// existing one and an AlreadyExists error is returned with it.
+func (c *StorageClusters) PlanCreate(ctx context.Context, cluster *StorageCluster) (*Plan, error) {
+	err := validationError("StorageCluster", cluster.ObjectMeta.Name, cluster.Spec.Validate())
+	if err != nil {
+		return nil, err
+	}
+	desired, err := desiredCephCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	current, err := c.Client.CephV1().CephClusters(c.Namespace).Get(ctx, cluster.ObjectMeta.Name, metav1.GetOptions{})
+	if err == nil {
+		plan, err := newPlan(PlanActionUpdate, "CephCluster", c.Namespace, current.ObjectMeta.Name, current, desired)
+		if err != nil {
+			return nil, err
+		}
+		return plan, newAlreadyExists(cephv1.Resource("cephclusters"), current.ObjectMeta.Name)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, wrapError(err)
+	}
+	return newPlan(PlanActionCreate, "CephCluster", c.Namespace, desired.ObjectMeta.Name, nil, desired)
+}
+
+// PlanUpdate returns the Ceph cluster Update would send without updating it.
+func (c *StorageClusters) PlanUpdate(ctx context.Context, cluster *StorageCluster) (*Plan, error) {
+	err := validationError("StorageCluster", cluster.ObjectMeta.Name, cluster.Spec.Validate())
+	if err != nil {
+		return nil, err
+	}
+	current, err := c.Client.CephV1().CephClusters(c.Namespace).Get(ctx, cluster.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	desired := current.DeepCopy()
+	err = updateCephCluster(desired, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return newPlan(PlanActionUpdate, "CephCluster", c.Namespace, current.ObjectMeta.Name, current, desired)
+}
+
+// PlanCreate returns the Ceph block pool Create would send without creating
+// it. If the Ceph block pool already exists, the plan diffs it against the
+// existing one and an AlreadyExists error is returned with it.
+func (p *StoragePools) PlanCreate(ctx context.Context, blockpool *StoragePool) (*Plan, error) {
+	blockpool = defaultedPool(blockpool)
+	err := validationError("StoragePool", blockpool.ObjectMeta.Name, blockpool.Spec.Validate(p.profile()))
+	if err != nil {
+		return nil, err
+	}
+	desired, err := desiredCephBlockPool(blockpool, p.profile())
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.Client.CephV1().CephBlockPools(p.Namespace).Get(ctx, blockpool.ObjectMeta.Name, metav1.GetOptions{})
+	if err == nil {
+		plan, err := newPlan(PlanActionUpdate, "CephBlockPool", p.Namespace, current.ObjectMeta.Name, current, desired)
+		if err != nil {
+			return nil, err
+		}
+		return plan, newAlreadyExists(cephv1.Resource("cephblockpools"), current.ObjectMeta.Name)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, wrapError(err)
+	}
+	return newPlan(PlanActionCreate, "CephBlockPool", desired.ObjectMeta.Namespace, desired.ObjectMeta.Name, nil, desired)
+}
+
+// PlanUpdate returns the Ceph block pool Update would send without updating
+// it.
+func (p *StoragePools) PlanUpdate(ctx context.Context, blockpool *StoragePool) (*Plan, error) {
+	blockpool = defaultedPool(blockpool)
+	err := validationError("StoragePool", blockpool.ObjectMeta.Name, blockpool.Spec.Validate(p.profile()))
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.Client.CephV1().CephBlockPools(p.Namespace).Get(ctx, blockpool.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	desired := current.DeepCopy()
+	err = updateCephBlockPool(desired, blockpool, p.profile())
+	if err != nil {
+		return nil, err
+	}
+	return newPlan(PlanActionUpdate, "CephBlockPool", p.Namespace, current.ObjectMeta.Name, current, desired)
+}
+
+// PlanCreate returns the storage class Create would send without creating
+// it. If the storage class already exists, the plan diffs it against the
+// existing one and an AlreadyExists error is returned with it.
+func (s *StorageVolumes) PlanCreate(ctx context.Context, volume *StorageVolume) (*Plan, error) {
+	err := validationError("StorageVolume", volume.ObjectMeta.Name, volume.Spec.Validate())
+	if err != nil {
+		return nil, err
+	}
+	desired := createBlockStorageClass(volume, s.Namespace)
+	current, err := s.KubeClient.StorageV1().StorageClasses().Get(ctx, desired.ObjectMeta.Name, metav1.GetOptions{})
+	if err == nil {
+		plan, err := newPlan(PlanActionUpdate, "StorageClass", "", current.ObjectMeta.Name, current, desired)
+		if err != nil {
+			return nil, err
+		}
+		return plan, newAlreadyExists(storageClassResource, current.ObjectMeta.Name)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, wrapError(err)
+	}
+	return newPlan(PlanActionCreate, "StorageClass", "", desired.ObjectMeta.Name, nil, desired)
+}
+
+// PlanUpdate returns the storage class Update would send without updating
+// it. Changes that recreate the storage class are planned with
+// PlanActionReplace.
+func (s *StorageVolumes) PlanUpdate(ctx context.Context, volume *StorageVolume) (*Plan, error) {
+	err := validationError("StorageVolume", volume.ObjectMeta.Name, volume.Spec.Validate())
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.getStorageClass(ctx, volume.ObjectMeta.Name)
+	if err != nil {
+		return nil, err
+	}
+	action := PlanActionUpdate
+	desired, recreate := updateStorageClass(current, createBlockStorageClass(volume, s.Namespace))
+	if recreate {
+		action = PlanActionReplace
+	}
+	return newPlan(action, "StorageClass", "", current.ObjectMeta.Name, current, desired)
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// writeActions returns the actions of the fake clientset other than reads.
+func writeActions(actions []k8stesting.Action) []k8stesting.Action {
+	var writes []k8stesting.Action
+
+	for _, action := range actions {
+		switch action.GetVerb() {
+		case "get", "list", "watch":
+		default:
+			writes = append(writes, action)
+		}
+	}
+	return writes
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       []string
+		b       []string
+		diff    string
+		changed bool
+	}{
+		{name: "unchanged", a: []string{"a", "b"}, b: []string{"a", "b"}, diff: "  a\n  b\n"},
+		{name: "created", a: nil, b: []string{"a"}, diff: "+ a\n", changed: true},
+		{name: "changed line", a: []string{"a", "b", "c"}, b: []string{"a", "x", "c"}, diff: "  a\n- b\n+ x\n  c\n", changed: true},
+		{name: "removed line", a: []string{"a", "b"}, b: []string{"b"}, diff: "- a\n  b\n", changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, changed := diffLines(tt.a, tt.b)
+			if diff != tt.diff || changed != tt.changed {
+				t.Errorf("expected %q %v, got %q %v", tt.diff, tt.changed, diff, changed)
+			}
+		})
+	}
+}
+
+func TestStoragePoolsPlan(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	plan, err := p.PlanCreate(context.TODO(), newQuotaPool("500Gi", 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionCreate || plan.Current != nil || !strings.Contains(plan.Diff, "+     maxSize: 500Gi") {
+		t.Errorf("unexpected plan %v %q", plan.Action, plan.Diff)
+	}
+	if writes := writeActions(client.Actions()); len(writes) != 0 {
+		t.Fatalf("expected no writes, got %v", writes)
+	}
+
+	if err := p.Create(newQuotaPool("500Gi", 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.ClearActions()
+	plan, err = p.PlanCreate(context.TODO(), newQuotaPool("500Gi", 0))
+	if !apierrors.IsAlreadyExists(err) || plan == nil {
+		t.Fatalf("expected already exists with a plan, got %v", err)
+	}
+	plan, err = p.PlanUpdate(context.TODO(), newQuotaPool("500Gi", 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionNone {
+		t.Errorf("expected no change, got %v %q", plan.Action, plan.Diff)
+	}
+	plan, err = p.PlanUpdate(context.TODO(), newQuotaPool("1Ti", 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionUpdate || !strings.Contains(plan.Diff, "-     maxSize: 500Gi") ||
+		!strings.Contains(plan.Diff, "+     maxSize: 1Ti") {
+		t.Errorf("unexpected plan %v %q", plan.Action, plan.Diff)
+	}
+	if writes := writeActions(client.Actions()); len(writes) != 0 {
+		t.Fatalf("expected no writes, got %v", writes)
+	}
+	pool, err := p.Get("bpool1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.Spec.Quota.String() != "500Gi" {
+		t.Errorf("expected the pool unchanged, got quota %s", pool.Spec.Quota.String())
+	}
+}
+
+func TestStorageClustersPlan(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client, KubeClient: kubefake.NewSimpleClientset()}
+	cluster := &StorageCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "rook-ceph"},
+		Spec:       StorageClusterSpec{Monitoring: newBool(true)},
+	}
+
+	plan, err := c.PlanCreate(context.TODO(), cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionCreate || plan.Kind != "CephCluster" {
+		t.Errorf("unexpected plan %v %s", plan.Action, plan.Kind)
+	}
+	if _, err := c.PlanUpdate(context.TODO(), cluster); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	if _, err := c.Create(cluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.ClearActions()
+	cluster.Spec.Monitoring = newBool(false)
+	plan, err = c.PlanUpdate(context.TODO(), cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionUpdate || !strings.Contains(plan.Diff, "-     enabled: true") {
+		t.Errorf("unexpected plan %v %q", plan.Action, plan.Diff)
+	}
+	if writes := writeActions(client.Actions()); len(writes) != 0 {
+		t.Fatalf("expected no writes, got %v", writes)
+	}
+}
+
+func TestStorageVolumesPlan(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	s := &StorageVolumes{Namespace: "rook-ceph", KubeClient: client}
+
+	plan, err := s.PlanCreate(context.TODO(), newBlockVolume("vol1", true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionCreate || plan.Name != "rook-ceph.vol1-block" {
+		t.Errorf("unexpected plan %v %s", plan.Action, plan.Name)
+	}
+
+	if _, _, err := s.Create(newBlockVolume("vol1", true)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.ClearActions()
+	plan, err = s.PlanUpdate(context.TODO(), newBlockVolume("vol1", true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionNone {
+		t.Errorf("expected no change, got %v %q", plan.Action, plan.Diff)
+	}
+	plan, err = s.PlanUpdate(context.TODO(), newBlockVolume("vol1", false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Action != PlanActionReplace || !strings.Contains(plan.Diff, "+ reclaimPolicy: Retain") {
+		t.Errorf("unexpected plan %v %q", plan.Action, plan.Diff)
+	}
+	if writes := writeActions(client.Actions()); len(writes) != 0 {
+		t.Fatalf("expected no writes, got %v", writes)
+	}
+}
@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Key of the mapping profile in its ConfigMap.
+const MappingProfileKey string = "profile.yaml"
+
+// ErasureCodedChunks are the data and coding chunks of an erasure coded
+// Ceph block pool.
+type ErasureCodedChunks struct {
+	DataChunks   uint `json:"datachunks"`
+	CodingChunks uint `json:"codingchunks"`
+}
+
+// MappingProfile maps the durability levels and performance classes of the
+// storage pools to the settings of their Ceph block pools. Get reverse maps
+// the Ceph block pools with the same profile, so no two levels or classes
+// may map to the same setting.
+type MappingProfile struct {
+	// Replica size of the replicated pools per durability level.
+	Replicated map[DurabilityLevel]uint `json:"replicated,omitempty"`
+
+	// Chunks of the erasure coded pools per durability level.
+	ErasureCoded map[DurabilityLevel]ErasureCodedChunks `json:"erasurecoded,omitempty"`
+
+	// Ceph device class per performance class.
+	DeviceClasses map[DevClass]string `json:"deviceclasses,omitempty"`
+}
+
+// defaultProfile is used by the clients without a profile, it is never
+// modified.
+var defaultProfile = DefaultMappingProfile()
+
+// DefaultMappingProfile returns the mappings documented on the types.
+func DefaultMappingProfile() *MappingProfile {
+	return &MappingProfile{
+		Replicated: map[DurabilityLevel]uint{
+			DurabilityLevelLow:    1,
+			DurabilityLevelSemi:   2,
+			DurabilityLevelNormal: 3,
+			DurabilityLevelHigh:   4,
+		},
+		ErasureCoded: map[DurabilityLevel]ErasureCodedChunks{
+			DurabilityLevelSemi:   {DataChunks: 2, CodingChunks: 1},
+			DurabilityLevelNormal: {DataChunks: 3, CodingChunks: 2},
+			DurabilityLevelHigh:   {DataChunks: 4, CodingChunks: 3},
+		},
+		DeviceClasses: map[DevClass]string{
+			DevStandard: "hdd",
+			DevMedium:   "ssd",
+			DevFast:     "nvme",
+		},
+	}
+}
+
+// levelRank orders the durability levels from low to high, the unknown
+// ones last.
+func levelRank(level DurabilityLevel) int {
+	for i, l := range supportedDurabilityLevels {
+		if string(level) == l {
+			return i
+		}
+	}
+	return len(supportedDurabilityLevels)
+}
+
+func sortLevels(levels []DurabilityLevel) []DurabilityLevel {
+	sort.Slice(levels, func(i, j int) bool {
+		if levelRank(levels[i]) != levelRank(levels[j]) {
+			return levelRank(levels[i]) < levelRank(levels[j])
+		}
+		return levels[i] < levels[j]
+	})
+	return levels
+}
+
+// Validate returns the errors of the profile, all of them at once.
+func (m *MappingProfile) Validate() field.ErrorList {
+	var errs field.ErrorList
+
+	var replicated, erasureCoded []DurabilityLevel
+	for level := range m.Replicated {
+		replicated = append(replicated, level)
+	}
+	for level := range m.ErasureCoded {
+		erasureCoded = append(erasureCoded, level)
+	}
+
+	sizes := map[uint]bool{}
+	for _, level := range sortLevels(replicated) {
+		size := m.Replicated[level]
+		path := field.NewPath("replicated").Key(string(level))
+		if !isSupported(string(level), supportedDurabilityLevels) {
+			errs = append(errs, field.NotSupported(path, level, supportedDurabilityLevels))
+		} else if sizes[size] {
+			errs = append(errs, field.Duplicate(path, int(size)))
+		}
+		sizes[size] = true
+	}
+	chunks := map[ErasureCodedChunks]bool{}
+	for _, level := range sortLevels(erasureCoded) {
+		c := m.ErasureCoded[level]
+		path := field.NewPath("erasurecoded").Key(string(level))
+		if !isSupported(string(level), supportedDurabilityLevels) {
+			errs = append(errs, field.NotSupported(path, level, supportedDurabilityLevels))
+		} else if c.DataChunks < 2 || c.CodingChunks < 1 {
+			errs = append(errs, field.Invalid(path, c, "requires at least 2 data chunks and 1 coding chunk"))
+		} else if chunks[c] {
+			errs = append(errs, field.Duplicate(path, c))
+		}
+		chunks[c] = true
+	}
+	deviceClasses := map[string]bool{}
+	var classes []string
+	for class := range m.DeviceClasses {
+		classes = append(classes, string(class))
+	}
+	sort.Strings(classes)
+	for _, c := range classes {
+		class := DevClass(c)
+		deviceClass := m.DeviceClasses[class]
+		path := field.NewPath("deviceclasses").Key(string(class))
+		if !isSupported(string(class), supportedDevClasses) {
+			errs = append(errs, field.NotSupported(path, class, supportedDevClasses))
+		} else if deviceClasses[deviceClass] {
+			errs = append(errs, field.Duplicate(path, deviceClass))
+		}
+		deviceClasses[deviceClass] = true
+	}
+	return errs
+}
+
+// ParseMappingProfile parses a YAML or JSON profile overriding the default
+// profile. The mappings it leaves out keep their default, a zero replica
+// size or chunk count and an empty device class remove the mapping.
+func ParseMappingProfile(data []byte) (*MappingProfile, error) {
+	var overrides MappingProfile
+
+	if err := yaml.UnmarshalStrict(data, &overrides); err != nil {
+		return nil, fmt.Errorf("Failed to parse mapping profile: %v", err)
+	}
+	profile := DefaultMappingProfile()
+	for level, size := range overrides.Replicated {
+		if size == 0 {
+			delete(profile.Replicated, level)
+		} else {
+			profile.Replicated[level] = size
+		}
+	}
+	for level, chunks := range overrides.ErasureCoded {
+		if chunks == (ErasureCodedChunks{}) {
+			delete(profile.ErasureCoded, level)
+		} else {
+			profile.ErasureCoded[level] = chunks
+		}
+	}
+	for class, deviceClass := range overrides.DeviceClasses {
+		if len(deviceClass) == 0 {
+			delete(profile.DeviceClasses, class)
+		} else {
+			profile.DeviceClasses[class] = deviceClass
+		}
+	}
+	if errs := profile.Validate(); len(errs) != 0 {
+		return nil, fmt.Errorf("Invalid mapping profile: %v", errs.ToAggregate())
+	}
+	return profile, nil
+}
+
+// LoadMappingProfileFile reads the mapping profile of a YAML or JSON file.
+func LoadMappingProfileFile(path string) (*MappingProfile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read mapping profile: %v", err)
+	}
+	return ParseMappingProfile(data)
+}
+
+// LoadMappingProfileConfigMap reads the mapping profile stored under
+// MappingProfileKey in a ConfigMap.
+func LoadMappingProfileConfigMap(ctx context.Context, kubeclnt kubernetes.Interface, namespace string, name string) (*MappingProfile, error) {
+	configMap, err := kubeclnt.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	data, ok := configMap.Data[MappingProfileKey]
+	if !ok {
+		return nil, fmt.Errorf("Missing %s in mapping profile ConfigMap %s/%s", MappingProfileKey, namespace, name)
+	}
+	return ParseMappingProfile([]byte(data))
+}
+
+// mapsLevel reports whether the profile maps the durability level for the
+// pools of the durability class.
+func (m *MappingProfile) mapsLevel(class DurabilityClass, level DurabilityLevel) bool {
+	var ok bool
+
+	switch class {
+	case DurabilityClassReplicated:
+		_, ok = m.Replicated[level]
+	case DurabilityClassErasureCoded:
+		_, ok = m.ErasureCoded[level]
+	}
+	return ok
+}
+
+// replicatedLevel reverse maps a replica size, "" if no level maps to it.
+func (m *MappingProfile) replicatedLevel(size uint) DurabilityLevel {
+	for level, s := range m.Replicated {
+		if s == size {
+			return level
+		}
+	}
+	return ""
+}
+
+// erasureCodedLevel reverse maps the chunks of an erasure coded pool. Pools
+// whose data chunks are not in the profile are mapped by their coding
+// chunks, if only one level has as many.
+func (m *MappingProfile) erasureCodedLevel(chunks ErasureCodedChunks) DurabilityLevel {
+	var match DurabilityLevel
+
+	for level, c := range m.ErasureCoded {
+		if c == chunks {
+			return level
+		}
+	}
+	for level, c := range m.ErasureCoded {
+		if c.CodingChunks == chunks.CodingChunks {
+			if len(match) != 0 {
+				return ""
+			}
+			match = level
+		}
+	}
+	return match
+}
+
+// devClass reverse maps a Ceph device class, "" if no class maps to it.
+func (m *MappingProfile) devClass(deviceClass string) DevClass {
+	for class, d := range m.DeviceClasses {
+		if d == deviceClass {
+			return class
+		}
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const siteProfile string = `
+erasurecoded:
+  high:
+    datachunks: 8
+    codingchunks: 3
+deviceclasses:
+  fast: optane
+replicated:
+  low: 0
+`
+
+func TestParseMappingProfile(t *testing.T) {
+	profile, err := ParseMappingProfile([]byte(siteProfile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := DefaultMappingProfile()
+	expected.ErasureCoded[DurabilityLevelHigh] = ErasureCodedChunks{DataChunks: 8, CodingChunks: 3}
+	expected.DeviceClasses[DevFast] = "optane"
+	delete(expected.Replicated, DurabilityLevelLow)
+	if !reflect.DeepEqual(profile, expected) {
+		t.Errorf("expected %+v, got %+v", expected, profile)
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		message string
+	}{
+		{name: "unknown field", profile: "replicas:\n  low: 1\n", message: "Failed to parse"},
+		{name: "duplicate size", profile: "replicated:\n  high: 3\n", message: "replicated[high]: Duplicate value: 3"},
+		{name: "unknown level", profile: "replicated:\n  extreme: 5\n", message: "replicated[extreme]: Unsupported value"},
+		{
+			name:    "too few chunks",
+			profile: "erasurecoded:\n  low:\n    datachunks: 1\n    codingchunks: 1\n",
+			message: "erasurecoded[low]: Invalid value",
+		},
+		{name: "duplicate device class", profile: "deviceclasses:\n  fast: ssd\n", message: "deviceclasses[medium]: Duplicate value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMappingProfile([]byte(tt.profile))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestLoadMappingProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	if err := ioutil.WriteFile(path, []byte(siteProfile), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromFile, err := LoadMappingProfileFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kubeclnt := kubefake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage-profile", Namespace: "rook-ceph"},
+		Data:       map[string]string{MappingProfileKey: siteProfile},
+	})
+	fromConfigMap, err := LoadMappingProfileConfigMap(context.Background(), kubeclnt, "rook-ceph", "storage-profile")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fromFile, fromConfigMap) {
+		t.Errorf("expected the same profile from the file and the ConfigMap, got %+v and %+v", fromFile, fromConfigMap)
+	}
+
+	_, err = LoadMappingProfileConfigMap(context.Background(), kubeclnt, "rook-ceph", "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoragePoolsProfile(t *testing.T) {
+	profile, err := ParseMappingProfile([]byte(siteProfile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := rookfake.NewSimpleClientset(newErasureCodedBlockPool("ec-legacy", "rook-ceph", 4, 3, "host", "nvme"))
+	p := &StoragePools{Namespace: "rook-ceph", Client: client, Profile: profile}
+
+	blockpool := newQuotaPool("1Gi", 0)
+	blockpool.Spec.DurabilityPolicy.DurabilityClass = DurabilityClassErasureCoded
+	blockpool.Spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelHigh
+	blockpool.Spec.PerfPolicy.IoPerfClass = DevFast
+	if err := p.Create(blockpool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool, err := client.CephV1().CephBlockPools("rook-ceph").Get(context.Background(), "bpool1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.Spec.ErasureCoded.DataChunks != 8 || pool.Spec.ErasureCoded.CodingChunks != 3 || pool.Spec.DeviceClass != "optane" {
+		t.Errorf("expected an 8+3 optane pool, got %+v", pool.Spec)
+	}
+
+	tests := []struct {
+		name      string
+		pool      string
+		level     DurabilityLevel
+		perfClass DevClass
+	}{
+		{name: "created with the profile", pool: "bpool1", level: DurabilityLevelHigh, perfClass: DevFast},
+		// Pools created with the default profile map by their coding chunks.
+		{name: "created with the default profile", pool: "ec-legacy", level: DurabilityLevelHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Get(tt.pool)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Spec.DurabilityPolicy.DurabilityLevel != tt.level || got.Spec.PerfPolicy.IoPerfClass != tt.perfClass {
+				t.Errorf("expected level %q and class %q, got %+v", tt.level, tt.perfClass, got.Spec)
+			}
+		})
+	}
+
+	lowPool := newQuotaPool("1Gi", 0)
+	lowPool.Name = "bpool2"
+	lowPool.Spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelLow
+	if err := p.Create(lowPool); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("expected ErrInvalidPolicy for a level removed from the profile, got %v", err)
+	}
+}
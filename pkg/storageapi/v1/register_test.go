@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestAddToScheme(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, kind := range []string{"StorageCluster", "StorageClusterList", "StoragePool", "StoragePoolList", "StorageVolume", "StorageVolumeList"} {
+		if !scheme.Recognizes(SchemeGroupVersion.WithKind(kind)) {
+			t.Errorf("kind %s not registered", kind)
+		}
+	}
+}
+
+func TestStoragePoolDeepCopy(t *testing.T) {
+	blockpool := &StoragePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "bpool1", Labels: map[string]string{"tier": "gold"}},
+		Spec: StoragePoolSpec{
+			ClusterID: "rook-ceph",
+			Quota:     resource.MustParse("10Gi"),
+		},
+	}
+	copied := blockpool.DeepCopyObject().(*StoragePool)
+	copied.ObjectMeta.Labels["tier"] = "silver"
+	copied.Spec.Quota.Add(resource.MustParse("1Gi"))
+	if blockpool.ObjectMeta.Labels["tier"] != "gold" || blockpool.Spec.Quota.String() != "10Gi" {
+		t.Errorf("deep copy shares state with the original: %+v", blockpool)
+	}
+}
+
+// TestCRDManifests checks the CRD manifests under artifacts are generated
+// for the registered kinds.
+func TestCRDManifests(t *testing.T) {
+	for _, plural := range []string{"storageclusters", "storagepools", "storagevolumes"} {
+		path := filepath.Join("..", "..", "..", "artifacts", "crds", GroupName+"_"+plural+".yaml")
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := yaml.Unmarshal(data, &crd); err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if crd.Spec.Group != GroupName || crd.Spec.Names.Plural != plural {
+			t.Errorf("%s: unexpected group %s plural %s", path, crd.Spec.Group, crd.Spec.Names.Plural)
+		}
+		if len(crd.Spec.Versions) != 1 || crd.Spec.Versions[0].Schema == nil || crd.Spec.Versions[0].Schema.OpenAPIV3Schema == nil {
+			t.Errorf("%s: expected an OpenAPI v3 schema", path)
+		}
+	}
+}
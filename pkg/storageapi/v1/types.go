@@ -1,16 +1,29 @@
 package v1
 
 import (
-	"net"
+	"encoding/json"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// NodeInfo identifies a node dedicated for storage.
+// +k8s:deepcopy-gen=true
 type NodeInfo struct {
-	hostName string
-	ipaddr   net.IP
+	// Kubernetes hostname of the node, storage daemons are
+	// scheduled on nodes by hostname.
+	HostName string `json:"hostname"`
+
+	// IP address of the node, for reference only.
+	IPAddress string `json:"ipaddress,omitempty"`
+
+	// Devices of the node to use for storage, by name ("sdb")
+	// or full path. If unspecified, all available devices of
+	// the node are used.
+	Devices []string `json:"devices,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=standard;medium;fast
 type DevClass string
 
 const (
@@ -19,6 +32,7 @@ const (
 	DevFast     DevClass = "fast"     // maps to NVMe
 )
 
+// +kubebuilder:validation:Enum=host;rack
 type FailureDomain string
 
 const (
@@ -45,6 +59,10 @@ const (
 // DurabilityLevel : normal --> dataChunks : 3 codingChunks: 2
 // DurabilityLevel : high --> dataChunks : 4 codingChunks: 3
 
+// These mappings and the device classes of the performance classes are
+// the DefaultMappingProfile, sites may load their own MappingProfile.
+
+// +kubebuilder:validation:Enum=replicated;erasurecoded
 type DurabilityClass string
 
 const (
@@ -52,6 +70,7 @@ const (
 	DurabilityClassErasureCoded DurabilityClass = "erasurecoded"
 )
 
+// +kubebuilder:validation:Enum=low;semi;normal;high
 type DurabilityLevel string
 
 const (
@@ -61,6 +80,7 @@ const (
 	DurabilityLevelHigh   DurabilityLevel = "high"
 )
 
+// +k8s:deepcopy-gen=true
 type StoragePolicyDurability struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -70,7 +90,7 @@ type StoragePolicyDurability struct {
 	// Can be one of "rack" or "host", extended later.
 	FailureDomain   FailureDomain   `json:"failuredomain"`
 	DurabilityClass DurabilityClass `json:"durabilityclass"`
-	DurabilityLevel DurabilityLevel `json: "redundancylevel"`
+	DurabilityLevel DurabilityLevel `json:"redundancylevel"`
 }
 
 // This policy specifies the level of storage performance desired.
@@ -80,6 +100,7 @@ type StoragePolicyDurability struct {
 // standard maps to rotational devices, HDD.
 // medium maps to SSD.
 // fast maps to NVMe.
+// +k8s:deepcopy-gen=true
 type StoragePolicyPerformance struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -92,10 +113,31 @@ type StoragePolicyPerformance struct {
 // A CRD based on this object is used to create the storage cluster.
 // A CRD based on this object is injected into the user cluster.
 
+// Connection settings of an external Ceph cluster.
+// The keys are stored in secrets of the storage cluster namespace.
+// +k8s:deepcopy-gen=true
+type ExternalClusterSpec struct {
+	// Monitor endpoints of the external cluster, as "ip:port".
+	MonEndpoints []string `json:"monendpoints"`
+
+	// Key of the client.admin user.
+	AdminKey string `json:"adminkey"`
+
+	// Keys of the CSI rbd node and provisioner users,
+	// required to provision volumes from the external cluster.
+	CSIRBDNodeKey        string `json:"csirbdnodekey,omitempty"`
+	CSIRBDProvisionerKey string `json:"csirbdprovisionerkey,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
 type StorageClusterSpec struct {
-	// Cluster ID of the storage cluster
+	// Cluster ID (FSID) of the storage cluster
 	// if consuming storage from an external cluster.
-	StorageClusterID string `json:"storageclusterid, omitempty"`
+	StorageClusterID string `json:"storageclusterid,omitempty"`
+
+	// Connection settings of the external cluster,
+	// required if StorageClusterID is set.
+	External *ExternalClusterSpec `json:"external,omitempty"`
 
 	// List of nodes which should be included as part of storage
 	// cluster, they are dedicated for storage. If unspecified, all nodes
@@ -103,7 +145,7 @@ type StorageClusterSpec struct {
 	Nodelist []NodeInfo `json:"nodelist,omitempty"`
 
 	// Prometheus monitoring, enabled by default.
-	Monitoring bool `json"monitoring,omitempty"`
+	Monitoring *bool `json:"monitoring,omitempty"`
 }
 
 type StorageClusterPhase string
@@ -131,6 +173,7 @@ const (
 	ClusterStateError      StorageClusterState = "Error"
 )
 
+// +k8s:deepcopy-gen=true
 type StorageClusterStatus struct {
 	// State indicates state of cluster
 	State StorageClusterState `json:"state,omitempty"`
@@ -142,33 +185,59 @@ type StorageClusterStatus struct {
 	Message string `json:"message,omitempty"`
 }
 
+// StorageCluster is a storage cluster, run by rook or external.
+// +genclient
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 type StorageCluster struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec   StorageClusterSpec   `json:"spec"`
-	Status StorageClusterStatus `json:"status"`
+	Status StorageClusterStatus `json:"status,omitempty"`
+}
+
+// StorageClusterList is a list of storage clusters, ListMeta carries
+// the continue token when the listing is paginated.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+type StorageClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StorageCluster `json:"items"`
 }
 
 // The storage pool object
 // A CRD based on this object is injected into the storage cluster.
 
+// +k8s:deepcopy-gen=true
 type StoragePoolSpec struct {
 	// This field specifies the storage cluster ID.
 	ClusterID string `json:"clusterid"`
 
-	// This field specifies any quota to set on the pool.
+	// This field specifies any quota to set on the pool, in bytes
+	// or as a human readable size such as "500Gi".
 	// if unspecified, default is to use all available capacity of the cluster.
-	Quota uint64 `json:"quota, omitempty"`
+	Quota resource.Quantity `json:"quota,omitempty"`
+
+	// This field specifies the maximum number of objects in the pool.
+	// if unspecified, the number of objects is not limited.
+	QuotaObjects uint64 `json:"quotaobjects,omitempty"`
 
 	// This field specifies any durability policy to set on the pool.
-	// if unspecified, default DurabilityClass is replicated,
-	// DurabilityLevel is normal.
-	DurabilityPolicy StoragePolicyDurability `json:"durabilitypolicy, omitempty"`
+	// if unspecified, default FailureDomain is host, DurabilityClass is
+	// replicated, DurabilityLevel is normal.
+	DurabilityPolicy StoragePolicyDurability `json:"durabilitypolicy,omitempty"`
 
 	// This field specifies the performance policy to set on the pool.
 	// if unspecified, default Ioperfclass is use to all available raw devices.
-	PerfPolicy StoragePolicyPerformance `json:"perfpolicy, omitempty"`
+	PerfPolicy StoragePolicyPerformance `json:"perfpolicy,omitempty"`
 }
 
 type StoragePoolPhase string
@@ -180,19 +249,80 @@ const (
 	PoolPhaseDeleting   StoragePoolPhase = "Deleting"
 )
 
+// Conditions of a storage pool.
+const (
+	// The Ceph block pool was changed behind the storage pool's back
+	// and reverted to its policies.
+	PoolConditionDrifted string = "Drifted"
+)
+
+// +k8s:deepcopy-gen=true
 type StoragePoolStatus struct {
 	// Phase indicates state of pool creation or deletion
 	Phase StoragePoolPhase `json:"phase,omitempty"`
+
+	// Generation of the storage pool last applied to the Ceph block pool.
+	ObservedGeneration int64 `json:"observedgeneration,omitempty"`
+
+	// Conditions of the pool, such as drift of the Ceph block pool.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// StoragePool is a block pool of a storage cluster.
+// +genclient
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 type StoragePool struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	Spec   StoragePoolSpec   `json:"spec"`
-	Status StoragePoolStatus `json:"status"`
+	Status StoragePoolStatus `json:"status,omitempty"`
 }
 
+// StoragePoolList is a list of storage pools, ListMeta carries
+// the continue token when the listing is paginated.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+type StoragePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StoragePool `json:"items"`
+}
+
+// StoragePoolFilter selects storage pools by policy.
+// Fields left empty match any pool.
+type StoragePoolFilter struct {
+	FailureDomain   FailureDomain
+	DurabilityClass DurabilityClass
+	DurabilityLevel DurabilityLevel
+	IoPerfClass     DevClass
+}
+
+// Matches reports whether the pool policies satisfy the filter.
+func (f *StoragePoolFilter) Matches(pool *StoragePool) bool {
+	dpolicy := pool.Spec.DurabilityPolicy
+	if f.FailureDomain != "" && f.FailureDomain != dpolicy.FailureDomain {
+		return false
+	}
+	if f.DurabilityClass != "" && f.DurabilityClass != dpolicy.DurabilityClass {
+		return false
+	}
+	if f.DurabilityLevel != "" && f.DurabilityLevel != dpolicy.DurabilityLevel {
+		return false
+	}
+	if f.IoPerfClass != "" && f.IoPerfClass != pool.Spec.PerfPolicy.IoPerfClass {
+		return false
+	}
+	return true
+}
+
+// +kubebuilder:validation:Enum=block
 type VolType string
 
 const (
@@ -204,8 +334,10 @@ type StorageVolumePhase string
 const (
 	VolumeCreated StorageVolumePhase = "Created"
 	VolumeDeleted StorageVolumePhase = "Deleted"
+	VolumeFailed  StorageVolumePhase = "Failed"
 )
 
+// +k8s:deepcopy-gen=true
 type StorageVolumeSpec struct {
 	// This field specifies whether this volume is block,
 	// file or object store.
@@ -221,34 +353,105 @@ type StorageVolumeSpec struct {
 	// This field specifies the filesystem of the volume
 	// to be mounted
 	// Defaults to ext4 if unspecified.
-	FSType string `json:"fstype, omitempty"`
+	FSType string `json:"fstype,omitempty"`
 
 	// This field specifies the mount option type of the volume
 	// to be mounted
 	// Defaults to False if unspecified.
-	ReadOnly bool `json:"readonly, omitempty"`
+	ReadOnly bool `json:"readonly,omitempty"`
 
 	// This field specifies whether data stored on this volume
 	// should be deleted after the claim is removed.
 	// Defaults to True if unspecified.
-	Reclaim bool `json:"phase,omitempty"`
+	Reclaim *bool `json:"reclaim,omitempty"`
+
+	// This field specifies whether claims using this volume
+	// can be expanded.
+	// Defaults to True if unspecified.
+	AllowExpansion *bool `json:"allowexpansion,omitempty"`
 }
 
+// +k8s:deepcopy-gen=true
 type StorageVolumeStatus struct {
 	// Phase indicates state of volume creation or deletion
-	Phase StorageVolumePhase
+	Phase StorageVolumePhase `json:"phase,omitempty"`
 
 	// Message provides an explanation of the volume phase
 	Message string `json:"message,omitempty"`
 
 	// Reason provides an explanation of the last failure
 	Reason string `json:"reason,omitempty"`
+
+	// Number of claims of the volume's storage class bound to a volume.
+	BoundClaims int32 `json:"boundclaims,omitempty"`
+
+	// Total capacity requested by the claims of the volume's storage class.
+	RequestedCapacity resource.Quantity `json:"requestedcapacity,omitempty"`
 }
 
+// StorageVolume is a volume type carved from a storage pool,
+// exposed to workloads as a storage class.
+// +genclient
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Pool",type=string,JSONPath=`.spec.pool`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 type StorageVolume struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   StorageVolumeSpec
-	Status StorageVolumeStatus
+	Spec   StorageVolumeSpec   `json:"spec"`
+	Status StorageVolumeStatus `json:"status,omitempty"`
+}
+
+// StorageVolumeList is a list of storage volumes, ListMeta carries
+// the continue token when the listing is paginated.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+type StorageVolumeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StorageVolume `json:"items"`
+}
+
+// UnmarshalJSON also accepts the level under "DurabilityLevel", the key
+// written before the redundancylevel tag was well formed.
+func (d *StoragePolicyDurability) UnmarshalJSON(data []byte) error {
+	type durability StoragePolicyDurability
+	var legacy struct {
+		durability
+		LegacyLevel DurabilityLevel `json:"DurabilityLevel,omitempty"`
+	}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*d = StoragePolicyDurability(legacy.durability)
+	if len(d.DurabilityLevel) == 0 {
+		d.DurabilityLevel = legacy.LegacyLevel
+	}
+	return nil
+}
+
+// UnmarshalJSON also accepts monitoring under "Monitoring", the key written
+// before the monitoring tag was well formed.
+func (s *StorageClusterSpec) UnmarshalJSON(data []byte) error {
+	type clusterSpec StorageClusterSpec
+	var legacy struct {
+		clusterSpec
+		LegacyMonitoring *bool `json:"Monitoring,omitempty"`
+	}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*s = StorageClusterSpec(legacy.clusterSpec)
+	if legacy.LegacyMonitoring != nil {
+		s.Monitoring = legacy.LegacyMonitoring
+	}
+	return nil
 }
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUnmarshalLegacyKeys(t *testing.T) {
+	var pool StoragePoolSpec
+	err := json.Unmarshal([]byte(`{"clusterid":"rook-ceph","durabilitypolicy":`+
+		`{"failuredomain":"rack","durabilityclass":"replicated","DurabilityLevel":"high"}}`), &pool)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.DurabilityPolicy.DurabilityLevel != DurabilityLevelHigh || pool.DurabilityPolicy.FailureDomain != FailureDomainRack {
+		t.Errorf("expected a high rack policy, got %+v", pool.DurabilityPolicy)
+	}
+
+	var cluster StorageClusterSpec
+	err = json.Unmarshal([]byte(`{"Monitoring":true,"nodelist":[{"hostname":"node1"}]}`), &cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Monitoring == nil || !*cluster.Monitoring || len(cluster.Nodelist) != 1 {
+		t.Errorf("expected monitoring and one node, got %+v", cluster)
+	}
+}
+
+func newBool(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	supportedFailureDomains   = []string{string(FailureDomainHost), string(FailureDomainRack)}
+	supportedDurabilityClass  = []string{string(DurabilityClassReplicated), string(DurabilityClassErasureCoded)}
+	supportedDurabilityLevels = []string{string(DurabilityLevelLow), string(DurabilityLevelSemi),
+		string(DurabilityLevelNormal), string(DurabilityLevelHigh)}
+	supportedDevClasses  = []string{string(DevStandard), string(DevMedium), string(DevFast)}
+	supportedVolumeTypes = []string{string(BlockVolume)}
+	supportedFSTypes     = []string{"ext4", "xfs"}
+)
+
+func isSupported(value string, supported []string) bool {
+	for _, s := range supported {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns the errors of the storage pool spec, all of them at
+// once, with the paths of the offending fields. The durability level and
+// performance class must be mapped by profile, nil is the default profile.
+func (s *StoragePoolSpec) Validate(profile *MappingProfile) field.ErrorList {
+	var errs field.ErrorList
+
+	if profile == nil {
+		profile = defaultProfile
+	}
+
+	path := field.NewPath("spec")
+	if len(s.ClusterID) == 0 {
+		errs = append(errs, field.Required(path.Child("clusterid"), ""))
+	}
+	if s.Quota.Sign() < 0 {
+		errs = append(errs, field.Invalid(path.Child("quota"), s.Quota.String(), "must not be negative"))
+	}
+
+	durability := s.DurabilityPolicy
+	durabilityPath := path.Child("durabilitypolicy")
+	if len(durability.FailureDomain) == 0 {
+		errs = append(errs, field.Required(durabilityPath.Child("failuredomain"), ""))
+	} else if !isSupported(string(durability.FailureDomain), supportedFailureDomains) {
+		errs = append(errs, field.NotSupported(durabilityPath.Child("failuredomain"), durability.FailureDomain, supportedFailureDomains))
+	}
+	if len(durability.DurabilityClass) == 0 {
+		errs = append(errs, field.Required(durabilityPath.Child("durabilityclass"), ""))
+	} else if !isSupported(string(durability.DurabilityClass), supportedDurabilityClass) {
+		errs = append(errs, field.NotSupported(durabilityPath.Child("durabilityclass"), durability.DurabilityClass, supportedDurabilityClass))
+	}
+	if len(durability.DurabilityLevel) == 0 {
+		errs = append(errs, field.Required(durabilityPath.Child("redundancylevel"), ""))
+	} else if !isSupported(string(durability.DurabilityLevel), supportedDurabilityLevels) {
+		errs = append(errs, field.NotSupported(durabilityPath.Child("redundancylevel"), durability.DurabilityLevel, supportedDurabilityLevels))
+	} else if isSupported(string(durability.DurabilityClass), supportedDurabilityClass) &&
+		!profile.mapsLevel(durability.DurabilityClass, durability.DurabilityLevel) {
+		errs = append(errs, field.Invalid(durabilityPath.Child("redundancylevel"), durability.DurabilityLevel,
+			fmt.Sprintf("not mapped for %s pools by the mapping profile", durability.DurabilityClass)))
+	}
+
+	perfClass := s.PerfPolicy.IoPerfClass
+	perfPath := path.Child("perfpolicy", "ioperfclass")
+	if len(perfClass) != 0 && !isSupported(string(perfClass), supportedDevClasses) {
+		errs = append(errs, field.NotSupported(perfPath, perfClass, supportedDevClasses))
+	} else if _, ok := profile.DeviceClasses[perfClass]; len(perfClass) != 0 && !ok {
+		errs = append(errs, field.Invalid(perfPath, perfClass, "not mapped to a device class by the mapping profile"))
+	}
+	return errs
+}
+
+// Validate returns the errors of the storage cluster spec, all of them at
+// once, with the paths of the offending fields.
+func (s *StorageClusterSpec) Validate() field.ErrorList {
+	var errs field.ErrorList
+
+	path := field.NewPath("spec")
+	if len(s.StorageClusterID) != 0 {
+		externalPath := path.Child("external")
+		if s.External == nil {
+			errs = append(errs, field.Required(externalPath, "required for an external cluster"))
+		} else {
+			if len(s.External.MonEndpoints) == 0 {
+				errs = append(errs, field.Required(externalPath.Child("monendpoints"), ""))
+			}
+			for i, endpoint := range s.External.MonEndpoints {
+				if _, _, err := net.SplitHostPort(endpoint); err != nil {
+					errs = append(errs, field.Invalid(externalPath.Child("monendpoints").Index(i), endpoint, "must be ip:port"))
+				}
+			}
+			if len(s.External.AdminKey) == 0 {
+				errs = append(errs, field.Required(externalPath.Child("adminkey"), ""))
+			}
+		}
+		if len(s.Nodelist) != 0 {
+			errs = append(errs, field.Forbidden(path.Child("nodelist"), "cannot be set on an external cluster"))
+		}
+	} else if s.External != nil {
+		errs = append(errs, field.Forbidden(path.Child("external"), "requires storageclusterid"))
+	}
+
+	hostnames := map[string]bool{}
+	for i, node := range s.Nodelist {
+		hostnamePath := path.Child("nodelist").Index(i).Child("hostname")
+		if len(node.HostName) == 0 {
+			errs = append(errs, field.Required(hostnamePath, ""))
+		} else if hostnames[node.HostName] {
+			errs = append(errs, field.Duplicate(hostnamePath, node.HostName))
+		}
+		hostnames[node.HostName] = true
+	}
+	return errs
+}
+
+// Validate returns the errors of the storage volume spec, all of them at
+// once, with the paths of the offending fields.
+func (s *StorageVolumeSpec) Validate() field.ErrorList {
+	var errs field.ErrorList
+
+	path := field.NewPath("spec")
+	if len(s.VolumeType) == 0 {
+		errs = append(errs, field.Required(path.Child("volumetype"), ""))
+	} else if !isSupported(string(s.VolumeType), supportedVolumeTypes) {
+		errs = append(errs, field.NotSupported(path.Child("volumetype"), s.VolumeType, supportedVolumeTypes))
+	}
+	if len(s.ClusterID) == 0 {
+		errs = append(errs, field.Required(path.Child("clusterid"), ""))
+	}
+	if len(s.PoolID) == 0 {
+		errs = append(errs, field.Required(path.Child("pool"), ""))
+	}
+	if len(s.FSType) != 0 && !isSupported(s.FSType, supportedFSTypes) {
+		errs = append(errs, field.NotSupported(path.Child("fstype"), s.FSType, supportedFSTypes))
+	}
+	return errs
+}
+
+// ValidateUpdate returns the errors of the storage pool spec updated from
+// old with the mappings of profile, the cluster and the durability class of
+// a pool cannot change. Pools stored without the defaults are compared to
+// their defaulted policies.
+func (s *StoragePoolSpec) ValidateUpdate(old *StoragePoolSpec, profile *MappingProfile) field.ErrorList {
+	path := field.NewPath("spec")
+	old = old.DeepCopy()
+	old.SetDefaults()
+	errs := s.Validate(profile)
+	errs = append(errs, apivalidation.ValidateImmutableField(s.ClusterID, old.ClusterID, path.Child("clusterid"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(s.DurabilityPolicy.DurabilityClass,
+		old.DurabilityPolicy.DurabilityClass, path.Child("durabilitypolicy", "durabilityclass"))...)
+	return errs
+}
+
+// ValidateUpdate returns the errors of the storage cluster spec updated
+// from old, a cluster cannot switch between internal and external mode or
+// to another external cluster.
+func (s *StorageClusterSpec) ValidateUpdate(old *StorageClusterSpec) field.ErrorList {
+	errs := s.Validate()
+	errs = append(errs, apivalidation.ValidateImmutableField(s.StorageClusterID, old.StorageClusterID,
+		field.NewPath("spec", "storageclusterid"))...)
+	return errs
+}
+
+// ValidateUpdate returns the errors of the storage volume spec updated
+// from old, the volume type cannot change.
+func (s *StorageVolumeSpec) ValidateUpdate(old *StorageVolumeSpec) field.ErrorList {
+	errs := s.Validate()
+	errs = append(errs, apivalidation.ValidateImmutableField(s.VolumeType, old.VolumeType,
+		field.NewPath("spec", "volumetype"))...)
+	return errs
+}
+
+// validationError returns the field errors of the named object as an
+// Invalid API error matching ErrInvalidPolicy, nil if there are none.
+func validationError(kind string, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &apiError{
+		target: ErrInvalidPolicy,
+		err:    apierrors.NewInvalid(SchemeGroupVersion.WithKind(kind).GroupKind(), name, errs),
+	}
+}
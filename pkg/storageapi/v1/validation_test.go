@@ -0,0 +1,278 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// errorFields returns the type and path of the field errors.
+func errorFields(errs field.ErrorList) []string {
+	var fields []string
+
+	for _, err := range errs {
+		fields = append(fields, string(err.Type)+" "+err.Field)
+	}
+	return fields
+}
+
+func TestStoragePoolSpecValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(spec *StoragePoolSpec)
+		fields []string
+	}{
+		{name: "valid", modify: func(spec *StoragePoolSpec) {}},
+		{
+			name: "low erasure coded",
+			modify: func(spec *StoragePoolSpec) {
+				spec.DurabilityPolicy.DurabilityClass = DurabilityClassErasureCoded
+				spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelLow
+			},
+			fields: []string{"FieldValueInvalid spec.durabilitypolicy.redundancylevel"},
+		},
+		{
+			name: "unknown failure domain and device class",
+			modify: func(spec *StoragePoolSpec) {
+				spec.DurabilityPolicy.FailureDomain = "zone"
+				spec.PerfPolicy.IoPerfClass = "optane"
+			},
+			fields: []string{
+				"FieldValueNotSupported spec.durabilitypolicy.failuredomain",
+				"FieldValueNotSupported spec.perfpolicy.ioperfclass",
+			},
+		},
+		{
+			name: "empty",
+			modify: func(spec *StoragePoolSpec) {
+				*spec = StoragePoolSpec{Quota: resource.MustParse("-1")}
+			},
+			fields: []string{
+				"FieldValueRequired spec.clusterid",
+				"FieldValueInvalid spec.quota",
+				"FieldValueRequired spec.durabilitypolicy.failuredomain",
+				"FieldValueRequired spec.durabilitypolicy.durabilityclass",
+				"FieldValueRequired spec.durabilitypolicy.redundancylevel",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := newQuotaPool("1Gi", 0).Spec
+			tt.modify(&spec)
+			if fields := errorFields(spec.Validate(nil)); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestStoragePoolSpecValidateProfile(t *testing.T) {
+	profile := DefaultMappingProfile()
+	delete(profile.Replicated, DurabilityLevelHigh)
+	delete(profile.DeviceClasses, DevFast)
+	profile.ErasureCoded[DurabilityLevelLow] = ErasureCodedChunks{DataChunks: 2, CodingChunks: 1}
+
+	tests := []struct {
+		name   string
+		modify func(spec *StoragePoolSpec)
+		fields []string
+	}{
+		{name: "mapped", modify: func(spec *StoragePoolSpec) {}},
+		{
+			name: "level removed by the profile",
+			modify: func(spec *StoragePoolSpec) {
+				spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelHigh
+			},
+			fields: []string{"FieldValueInvalid spec.durabilitypolicy.redundancylevel"},
+		},
+		{
+			name: "low erasure coded mapped by the profile",
+			modify: func(spec *StoragePoolSpec) {
+				spec.DurabilityPolicy.DurabilityClass = DurabilityClassErasureCoded
+				spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelLow
+			},
+		},
+		{
+			name: "device class removed by the profile",
+			modify: func(spec *StoragePoolSpec) {
+				spec.PerfPolicy.IoPerfClass = DevFast
+			},
+			fields: []string{"FieldValueInvalid spec.perfpolicy.ioperfclass"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := newQuotaPool("1Gi", 0).Spec
+			tt.modify(&spec)
+			if fields := errorFields(spec.Validate(profile)); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestStorageClusterSpecValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   StorageClusterSpec
+		fields []string
+	}{
+		{name: "internal", spec: StorageClusterSpec{Nodelist: []NodeInfo{{HostName: "node1"}, {HostName: "node2"}}}},
+		{name: "external", spec: newExternalCluster("fsid", "10.0.0.1:6789").Spec},
+		{
+			name: "external without settings",
+			spec: StorageClusterSpec{StorageClusterID: "fsid", Nodelist: []NodeInfo{{HostName: "node1"}}},
+			fields: []string{
+				"FieldValueRequired spec.external",
+				"FieldValueForbidden spec.nodelist",
+			},
+		},
+		{
+			name: "external with invalid settings",
+			spec: StorageClusterSpec{StorageClusterID: "fsid", External: &ExternalClusterSpec{MonEndpoints: []string{"10.0.0.1"}}},
+			fields: []string{
+				"FieldValueInvalid spec.external.monendpoints[0]",
+				"FieldValueRequired spec.external.adminkey",
+			},
+		},
+		{
+			name:   "external settings without cluster ID",
+			spec:   StorageClusterSpec{External: &ExternalClusterSpec{}},
+			fields: []string{"FieldValueForbidden spec.external"},
+		},
+		{
+			name: "invalid nodes",
+			spec: StorageClusterSpec{Nodelist: []NodeInfo{{HostName: "node1"}, {}, {HostName: "node1"}}},
+			fields: []string{
+				"FieldValueRequired spec.nodelist[1].hostname",
+				"FieldValueDuplicate spec.nodelist[2].hostname",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fields := errorFields(tt.spec.Validate()); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestStorageVolumeSpecValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   StorageVolumeSpec
+		fields []string
+	}{
+		{name: "valid", spec: newBlockVolume("vol1", true).Spec},
+		{
+			name: "unsupported",
+			spec: StorageVolumeSpec{VolumeType: "file", ClusterID: "rook-ceph", PoolID: "bpool1", FSType: "ntfs"},
+			fields: []string{
+				"FieldValueNotSupported spec.volumetype",
+				"FieldValueNotSupported spec.fstype",
+			},
+		},
+		{
+			name: "empty",
+			spec: StorageVolumeSpec{},
+			fields: []string{
+				"FieldValueRequired spec.volumetype",
+				"FieldValueRequired spec.clusterid",
+				"FieldValueRequired spec.pool",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if fields := errorFields(tt.spec.Validate()); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestStoragePoolSpecValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(old *StoragePoolSpec, spec *StoragePoolSpec)
+		fields []string
+	}{
+		{
+			name: "durability level change",
+			modify: func(old *StoragePoolSpec, spec *StoragePoolSpec) {
+				spec.DurabilityPolicy.DurabilityLevel = DurabilityLevelHigh
+			},
+		},
+		{
+			name: "old pool without defaults",
+			modify: func(old *StoragePoolSpec, spec *StoragePoolSpec) {
+				old.DurabilityPolicy = StoragePolicyDurability{}
+			},
+		},
+		{
+			name: "cluster and durability class change",
+			modify: func(old *StoragePoolSpec, spec *StoragePoolSpec) {
+				spec.ClusterID = "rook-ceph2"
+				spec.DurabilityPolicy.DurabilityClass = DurabilityClassErasureCoded
+			},
+			fields: []string{
+				"FieldValueInvalid spec.clusterid",
+				"FieldValueInvalid spec.durabilitypolicy.durabilityclass",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := newQuotaPool("1Gi", 0).Spec
+			spec := newQuotaPool("1Gi", 0).Spec
+			tt.modify(&old, &spec)
+			if fields := errorFields(spec.ValidateUpdate(&old, nil)); !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestStorageClusterAndVolumeSpecValidateUpdate(t *testing.T) {
+	cluster := newExternalCluster("fsid", "10.0.0.1:6789").Spec
+	otherCluster := newExternalCluster("fsid2", "10.0.0.1:6789").Spec
+	if fields := errorFields(otherCluster.ValidateUpdate(&cluster)); !reflect.DeepEqual(fields, []string{"FieldValueInvalid spec.storageclusterid"}) {
+		t.Errorf("expected a storageclusterid change error, got %v", fields)
+	}
+	volume := newBlockVolume("vol1", true).Spec
+	fileVolume := volume
+	fileVolume.VolumeType = "file"
+	fields := errorFields(fileVolume.ValidateUpdate(&volume))
+	if !reflect.DeepEqual(fields, []string{"FieldValueNotSupported spec.volumetype", "FieldValueInvalid spec.volumetype"}) {
+		t.Errorf("expected volumetype errors, got %v", fields)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	pool := StoragePoolSpec{DurabilityPolicy: StoragePolicyDurability{DurabilityLevel: DurabilityLevelHigh}}
+	pool.SetDefaults()
+	expected := StoragePolicyDurability{
+		FailureDomain:   FailureDomainHost,
+		DurabilityClass: DurabilityClassReplicated,
+		DurabilityLevel: DurabilityLevelHigh,
+	}
+	if !reflect.DeepEqual(pool.DurabilityPolicy, expected) {
+		t.Errorf("expected %+v, got %+v", expected, pool.DurabilityPolicy)
+	}
+
+	volume := StorageVolumeSpec{FSType: "xfs"}
+	volume.SetDefaults()
+	if volume.FSType != "xfs" || volume.AllowExpansion == nil || !*volume.AllowExpansion ||
+		volume.Reclaim == nil || !*volume.Reclaim {
+		t.Errorf("expected xfs, reclaim and allowed expansion, got %+v", volume)
+	}
+}
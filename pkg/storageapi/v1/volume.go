@@ -1,84 +1,377 @@
 package v1
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/go-logr/logr"
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
 )
 
 type VolumeInterface interface {
-	Create(volume *StorageVolume) (*StorageVolume, *string, error)
+	Create(volume *StorageVolume) (*StorageVolume, *storagev1.StorageClass, error)
+	Update(volume *StorageVolume) (*StorageVolume, error)
 	Delete(volumename string) error
+	List(opts metav1.ListOptions) (*StorageVolumeList, error)
+	Get(volumename string) (*StorageVolume, error)
+	CreateContext(ctx context.Context, volume *StorageVolume) (*StorageVolume, *storagev1.StorageClass, error)
+	UpdateContext(ctx context.Context, volume *StorageVolume) (*StorageVolume, error)
+	DeleteContext(ctx context.Context, volumename string) error
+	ListContext(ctx context.Context, opts metav1.ListOptions) (*StorageVolumeList, error)
+	GetContext(ctx context.Context, volumename string) (*StorageVolume, error)
 }
 
+const (
+	// Labels set on the storage class to tie it back to its storage volume.
+	volumeNameLabel      string = "storageapi.rookclient.io/volume"
+	volumeNamespaceLabel string = "storageapi.rookclient.io/namespace"
+	volumeTypeLabel      string = "storageapi.rookclient.io/volumetype"
+
+	fstypeParameter     string = "csi.storage.k8s.io/fstype"
+	readOnlyMountOption string = "ro"
+)
+
+var storageClassResource = schema.GroupResource{Group: "storage.k8s.io", Resource: "storageclasses"}
+
 type StorageVolumes struct {
-	Namespace string
-	Client    *rookclient.Clientset
+	Namespace  string
+	Client     rookclient.Interface
+	KubeClient kubernetes.Interface
+	// Log receives the operations of the client, nil discards them.
+	Log logr.Logger
+}
+
+var _ VolumeInterface = &StorageVolumes{}
+
+// StorageClassName returns the name of the storage class of the volume.
+// Storage classes are cluster scoped, the namespace of the volume is part of
+// the name so that volumes of the same name in other namespaces do not
+// collide. Namespaces cannot contain dots, which keeps the name unambiguous.
+func StorageClassName(namespace string, volumename string) string {
+	return namespace + "." + volumename + "-block"
+}
+
+// ParseStorageClassName returns the namespace and name of the volume of a
+// storage class named by StorageClassName, ok is false for other names.
+func ParseStorageClassName(class string) (namespace string, volumename string, ok bool) {
+	i := strings.Index(class, ".")
+	if i <= 0 || !strings.HasSuffix(class, "-block") || len(class)-len("-block") <= i+1 {
+		return "", "", false
+	}
+	return class[:i], class[i+1 : len(class)-len("-block")], true
 }
 
-func createBlockStorageClass(volume *StorageVolume) string {
-	var reclaimPolicy string
+// createBlockStorageClass builds the rbd storage class backing the volume,
+// labelled with the owning volume.
+func createBlockStorageClass(volume *StorageVolume, namespace string) *storagev1.StorageClass {
+	var reclaimPolicy corev1.PersistentVolumeReclaimPolicy
+	var mountOptions []string
 
-	storageClassName := volume.ObjectMeta.Name + "-block"
 	poolName := volume.Spec.PoolID
-	if volume.Spec.Reclaim == true {
-		reclaimPolicy = "Delete"
+	if volume.Spec.Reclaim == nil || *volume.Spec.Reclaim {
+		reclaimPolicy = corev1.PersistentVolumeReclaimDelete
 	} else {
-		reclaimPolicy = "Retain"
+		reclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	}
+	fstype := volume.Spec.FSType
+	if len(fstype) == 0 {
+		fstype = DefaultFSType
+	}
+	allowExpansion := true
+	if volume.Spec.AllowExpansion != nil {
+		allowExpansion = *volume.Spec.AllowExpansion
 	}
+	if volume.Spec.ReadOnly == true {
+		mountOptions = []string{readOnlyMountOption}
+	}
+	// The csi driver and its secrets live in the namespace of the rook
+	// cluster, which is the cluster ID, not in the namespace of the volume.
 	clusterid := volume.Spec.ClusterID
-	namespace := volume.ObjectMeta.Namespace
-
-	return `
-apiVersion: storage.k8s.io/v1
-kind: StorageClass
-metadata:
-  name: ` + storageClassName + `
-provisioner: ` + namespace + `.rbd.csi.ceph.com
-parameters:
-  clusterID: ` + clusterid + `
-  pool: ` + poolName + `
-  imageFormat: "2"
-  imageFeatures: layering
-  csi.storage.k8s.io/provisioner-secret-name: rook-csi-rbd-provisioner
-  csi.storage.k8s.io/provisioner-secret-namespace: ` + namespace + `
-  csi.storage.k8s.io/controller-expand-secret-name: rook-csi-rbd-provisioner
-  csi.storage.k8s.io/controller-expand-secret-namespace: ` + namespace + `
-  csi.storage.k8s.io/node-stage-secret-name: rook-csi-rbd-node
-  csi.storage.k8s.io/node-stage-secret-namespace: ` + namespace + `
-  csi.storage.k8s.io/fstype: ext4
-allowVolumeExpansion: true
-reclaimPolicy: ` + reclaimPolicy + `
-`
-}
-
-func (s *StorageVolumes) Create(volume *StorageVolume) (*StorageVolume, *string, error) {
-	var sc string
-
-	if volume.Spec.VolumeType != BlockVolume {
-		err := fmt.Errorf(" Invalid volume type, cannot create volume")
-		return nil, &sc, err
-	}
-
-	sc = createBlockStorageClass(volume)
+
+	return &storagev1.StorageClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: storagev1.SchemeGroupVersion.String(),
+			Kind:       "StorageClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: StorageClassName(namespace, volume.ObjectMeta.Name),
+			Labels: map[string]string{
+				volumeNameLabel:      volume.ObjectMeta.Name,
+				volumeNamespaceLabel: namespace,
+				volumeTypeLabel:      string(volume.Spec.VolumeType),
+			},
+		},
+		Provisioner: clusterid + ".rbd.csi.ceph.com",
+		Parameters: map[string]string{
+			"clusterID":     clusterid,
+			"pool":          poolName,
+			"imageFormat":   "2",
+			"imageFeatures": "layering",
+			"csi.storage.k8s.io/provisioner-secret-name":            "rook-csi-rbd-provisioner",
+			"csi.storage.k8s.io/provisioner-secret-namespace":       clusterid,
+			"csi.storage.k8s.io/controller-expand-secret-name":      "rook-csi-rbd-provisioner",
+			"csi.storage.k8s.io/controller-expand-secret-namespace": clusterid,
+			"csi.storage.k8s.io/node-stage-secret-name":             "rook-csi-rbd-node",
+			"csi.storage.k8s.io/node-stage-secret-namespace":        clusterid,
+			fstypeParameter: fstype,
+		},
+		AllowVolumeExpansion: &allowExpansion,
+		ReclaimPolicy:        &reclaimPolicy,
+		MountOptions:         mountOptions,
+	}
+}
+
+// StorageClassYAML serializes the storage class returned by Create as YAML.
+func StorageClassYAML(class *storagev1.StorageClass) ([]byte, error) {
+	return yaml.Marshal(class)
+}
+
+// StorageClassJSON serializes the storage class returned by Create as JSON.
+func StorageClassJSON(class *storagev1.StorageClass) ([]byte, error) {
+	return json.MarshalIndent(class, "", "  ")
+}
+
+// newStorageVolume reverse maps a storage class created by Create into
+// its storage volume.
+func newStorageVolume(class *storagev1.StorageClass) *StorageVolume {
+	var reclaim bool
+	var readOnly bool
+	allowExpansion := false
+
+	if class.ReclaimPolicy == nil || *class.ReclaimPolicy == corev1.PersistentVolumeReclaimDelete {
+		reclaim = true
+	}
+	for _, option := range class.MountOptions {
+		if option == readOnlyMountOption {
+			readOnly = true
+		}
+	}
+	if class.AllowVolumeExpansion != nil {
+		allowExpansion = *class.AllowVolumeExpansion
+	}
+
+	return &StorageVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      class.ObjectMeta.Labels[volumeNameLabel],
+			Namespace: class.ObjectMeta.Labels[volumeNamespaceLabel],
+		},
+		Spec: StorageVolumeSpec{
+			VolumeType:     VolType(class.ObjectMeta.Labels[volumeTypeLabel]),
+			ClusterID:      class.Parameters["clusterID"],
+			PoolID:         class.Parameters["pool"],
+			FSType:         class.Parameters[fstypeParameter],
+			ReadOnly:       readOnly,
+			Reclaim:        &reclaim,
+			AllowExpansion: &allowExpansion,
+		},
+		Status: StorageVolumeStatus{
+			Phase: VolumeCreated,
+		},
+	}
+}
+
+// isOwnedBy reports whether the storage class belongs to the named
+// volume in the namespace.
+func isOwnedBy(class *storagev1.StorageClass, volumename string, namespace string) bool {
+	return class.ObjectMeta.Labels[volumeNameLabel] == volumename &&
+		class.ObjectMeta.Labels[volumeNamespaceLabel] == namespace
+}
+
+func volumeNotFound(namespace string, volumename string) error {
+	return wrapError(apierrors.NewNotFound(storageClassResource, StorageClassName(namespace, volumename)))
+}
+
+// getStorageClass returns the storage class of the volume, classes not
+// created for this volume are reported as not found.
+func (s *StorageVolumes) getStorageClass(ctx context.Context, volumename string) (*storagev1.StorageClass, error) {
+	class, err := s.KubeClient.StorageV1().StorageClasses().Get(ctx, StorageClassName(s.Namespace, volumename), metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !isOwnedBy(class, volumename, s.Namespace) {
+		return nil, volumeNotFound(s.Namespace, volumename)
+	}
+	return class, nil
+}
+
+func (s *StorageVolumes) CreateContext(ctx context.Context, volume *StorageVolume) (*StorageVolume, *storagev1.StorageClass, error) {
+	err := validationError("StorageVolume", volume.ObjectMeta.Name, volume.Spec.Validate())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	class := createBlockStorageClass(volume, s.Namespace)
+	_, err = s.KubeClient.StorageV1().StorageClasses().Create(ctx, class, metav1.CreateOptions{})
+	if err != nil {
+		volume.Status.Reason = err.Error()
+		return volume, class, wrapError(err)
+	}
+	operationLogger(s.Log, "create", s.Namespace, volume.ObjectMeta.Name).Info("Storage class created",
+		"storageclass", class.ObjectMeta.Name)
+	volume.Status.Phase = VolumeCreated
+	return volume, class, nil
+}
+
+// Create is CreateContext with a background context.
+func (s *StorageVolumes) Create(volume *StorageVolume) (*StorageVolume, *storagev1.StorageClass, error) {
+	return s.CreateContext(context.Background(), volume)
+}
+
+// updateStorageClass returns the current storage class updated to the
+// desired one, or the desired class and true when the change requires the
+// storage class to be recreated.
+func updateStorageClass(class *storagev1.StorageClass, desired *storagev1.StorageClass) (*storagev1.StorageClass, bool) {
+	if class.Provisioner != desired.Provisioner ||
+		!reflect.DeepEqual(class.Parameters, desired.Parameters) ||
+		!reflect.DeepEqual(class.ReclaimPolicy, desired.ReclaimPolicy) ||
+		!reflect.DeepEqual(class.MountOptions, desired.MountOptions) {
+		return desired, true
+	}
+	updated := class.DeepCopy()
+	updated.AllowVolumeExpansion = desired.AllowVolumeExpansion
+	updated.ObjectMeta.Labels = desired.ObjectMeta.Labels
+	return updated, false
+}
+
+// UpdateContext applies the volume spec to its storage class. Kubernetes only
+// allows allowVolumeExpansion to change in place, any other change
+// such as the reclaim policy recreates the storage class.
+func (s *StorageVolumes) UpdateContext(ctx context.Context, volume *StorageVolume) (*StorageVolume, error) {
+	err := validationError("StorageVolume", volume.ObjectMeta.Name, volume.Spec.Validate())
+	if err != nil {
+		return nil, err
+	}
+
+	volumename := volume.ObjectMeta.Name
+	log := operationLogger(s.Log, "update", s.Namespace, volumename)
+	desired := createBlockStorageClass(volume, s.Namespace)
+	storageclasses := s.KubeClient.StorageV1().StorageClasses()
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		class, err := s.getStorageClass(ctx, volumename)
+		if err != nil {
+			return err
+		}
+		updated, recreate := updateStorageClass(class, desired)
+		if recreate {
+			err = storageclasses.Delete(ctx, class.ObjectMeta.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			_, err = storageclasses.Create(ctx, desired, metav1.CreateOptions{})
+			if err != nil {
+				return s.restoreStorageClass(ctx, class, err)
+			}
+			log.Info("Storage class recreated", "storageclass", desired.ObjectMeta.Name)
+			return nil
+		}
+		_, err = storageclasses.Update(ctx, updated, metav1.UpdateOptions{})
+		if err == nil {
+			log.Info("Storage class updated", "storageclass", updated.ObjectMeta.Name)
+		}
+		return err
+	})
+	if err != nil {
+		volume.Status.Reason = err.Error()
+		return volume, wrapError(err)
+	}
+
 	volume.Status.Phase = VolumeCreated
-	return volume, &sc, nil
+	return volume, nil
 }
 
+// restoreStorageClass recreates the deleted storage class after its
+// replacement failed to be created with createErr, so that the volume is
+// not left without a storage class.
+func (s *StorageVolumes) restoreStorageClass(ctx context.Context, class *storagev1.StorageClass, createErr error) error {
+	restored := class.DeepCopy()
+	restored.ObjectMeta.ResourceVersion = ""
+	restored.ObjectMeta.UID = ""
+	_, err := s.KubeClient.StorageV1().StorageClasses().Create(ctx, restored, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("Storage class %s was deleted and could not be recreated: %v, restoring it failed: %v",
+			class.ObjectMeta.Name, createErr, err)
+	}
+	return fmt.Errorf("Failed to recreate storage class %s, the previous storage class was restored: %v",
+		class.ObjectMeta.Name, createErr)
+}
+
+// Update is UpdateContext with a background context.
 func (s *StorageVolumes) Update(volume *StorageVolume) (*StorageVolume, error) {
-	return nil, nil
+	return s.UpdateContext(context.Background(), volume)
 }
 
+func (s *StorageVolumes) DeleteContext(ctx context.Context, volumename string) error {
+	class, err := s.getStorageClass(ctx, volumename)
+	if err != nil {
+		return err
+	}
+	err = s.KubeClient.StorageV1().StorageClasses().Delete(ctx, class.ObjectMeta.Name, metav1.DeleteOptions{})
+	if err == nil {
+		operationLogger(s.Log, "delete", s.Namespace, volumename).Info("Storage class deleted",
+			"storageclass", class.ObjectMeta.Name)
+	}
+	return wrapError(err)
+}
+
+// Delete is DeleteContext with a background context.
 func (s *StorageVolumes) Delete(volumename string) error {
-	return nil
+	return s.DeleteContext(context.Background(), volumename)
 }
 
+func (s *StorageVolumes) GetContext(ctx context.Context, volumename string) (*StorageVolume, error) {
+	class, err := s.getStorageClass(ctx, volumename)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStorageVolume(class), nil
+}
+
+// Get is GetContext with a background context.
 func (s *StorageVolumes) Get(volumename string) (*StorageVolume, error) {
-	return nil, nil
+	return s.GetContext(context.Background(), volumename)
 }
 
-func (s *StorageVolumes) List() ([]StorageVolume, error) {
-	var vlist []StorageVolume
+// ListContext returns the storage volumes of the namespace, opts may carry
+// additional label selectors and pagination.
+func (s *StorageVolumes) ListContext(ctx context.Context, opts metav1.ListOptions) (*StorageVolumeList, error) {
+	selector := volumeNamespaceLabel + "=" + s.Namespace + "," + volumeNameLabel
+	if len(opts.LabelSelector) != 0 {
+		selector = selector + "," + opts.LabelSelector
+	}
+	opts.LabelSelector = selector
+	classes, err := s.KubeClient.StorageV1().StorageClasses().List(ctx, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	vlist := &StorageVolumeList{
+		ListMeta: metav1.ListMeta{
+			ResourceVersion:    classes.ListMeta.ResourceVersion,
+			Continue:           classes.ListMeta.Continue,
+			RemainingItemCount: classes.ListMeta.RemainingItemCount,
+		},
+		Items: make([]StorageVolume, 0, len(classes.Items)),
+	}
+	for i := range classes.Items {
+		vlist.Items = append(vlist.Items, *newStorageVolume(&classes.Items[i]))
+	}
 
 	return vlist, nil
 }
+
+// List is ListContext with a background context.
+func (s *StorageVolumes) List(opts metav1.ListOptions) (*StorageVolumeList, error) {
+	return s.ListContext(context.Background(), opts)
+}
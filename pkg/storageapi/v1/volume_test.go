@@ -0,0 +1,358 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func newBlockVolume(name string, reclaim bool) *StorageVolume {
+	return &StorageVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "rook-ceph",
+		},
+		Spec: StorageVolumeSpec{
+			VolumeType: BlockVolume,
+			ClusterID:  "rook-ceph",
+			PoolID:     "bpool1",
+			FSType:     "xfs",
+			Reclaim:    &reclaim,
+		},
+	}
+}
+
+func TestStorageVolumesLifecycle(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	s := &StorageVolumes{Namespace: "rook-ceph", KubeClient: client}
+
+	_, _, err := s.Create(newBlockVolume("vol1", true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	class, err := client.StorageV1().StorageClasses().Get(context.TODO(), "rook-ceph.vol1-block", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("storage class not created: %v", err)
+	}
+	if class.ObjectMeta.Labels[volumeNameLabel] != "vol1" {
+		t.Errorf("expected owner label vol1, got %v", class.ObjectMeta.Labels)
+	}
+
+	volume, err := s.Get("vol1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if volume.Spec.PoolID != "bpool1" || volume.Spec.FSType != "xfs" || !*volume.Spec.Reclaim {
+		t.Errorf("unexpected volume spec %+v", volume.Spec)
+	}
+	if volume.Spec.AllowExpansion == nil || !*volume.Spec.AllowExpansion {
+		t.Errorf("expected expansion allowed by default")
+	}
+
+	_, _, err = s.Create(newBlockVolume("vol2", false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vlist, err := s.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vlist.Items) != 2 {
+		t.Fatalf("expected 2 volumes, got %d", len(vlist.Items))
+	}
+
+	other := &StorageVolumes{Namespace: "other", KubeClient: client}
+	vlist, err = other.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vlist.Items) != 0 {
+		t.Errorf("expected no volumes in other namespace, got %d", len(vlist.Items))
+	}
+	if _, err = other.Get("vol1"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found from other namespace, got %v", err)
+	}
+
+	if err = s.Delete("vol1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = s.Get("vol1"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestStorageVolumesUpdate(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name      string
+		update    func(volume *StorageVolume)
+		recreated bool
+	}{
+		{
+			name: "expansion updated in place",
+			update: func(volume *StorageVolume) {
+				volume.Spec.AllowExpansion = &disabled
+			},
+			recreated: false,
+		},
+		{
+			name: "reclaim policy recreates class",
+			update: func(volume *StorageVolume) {
+				volume.Spec.Reclaim = &disabled
+			},
+			recreated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset()
+			s := &StorageVolumes{Namespace: "rook-ceph", KubeClient: client}
+			if _, _, err := s.Create(newBlockVolume("vol1", true)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			client.ClearActions()
+
+			volume := newBlockVolume("vol1", true)
+			tt.update(volume)
+			if _, err := s.Update(volume); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			recreated := false
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "delete" {
+					recreated = true
+				}
+			}
+			if recreated != tt.recreated {
+				t.Errorf("expected recreated %v, got %v", tt.recreated, recreated)
+			}
+
+			class, err := client.StorageV1().StorageClasses().Get(context.TODO(), "rook-ceph.vol1-block", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectedReclaim := corev1.PersistentVolumeReclaimDelete
+			if !*volume.Spec.Reclaim {
+				expectedReclaim = corev1.PersistentVolumeReclaimRetain
+			}
+			if *class.ReclaimPolicy != expectedReclaim {
+				t.Errorf("expected reclaim policy %s, got %s", expectedReclaim, *class.ReclaimPolicy)
+			}
+			expectedExpansion := volume.Spec.AllowExpansion == nil || *volume.Spec.AllowExpansion
+			if *class.AllowVolumeExpansion != expectedExpansion {
+				t.Errorf("expected expansion %v, got %v", expectedExpansion, *class.AllowVolumeExpansion)
+			}
+		})
+	}
+}
+
+func TestStorageVolumesUpdateNotFound(t *testing.T) {
+	s := &StorageVolumes{Namespace: "rook-ceph", KubeClient: kubefake.NewSimpleClientset()}
+
+	_, err := s.Update(newBlockVolume("missing", true))
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestCreateBlockStorageClassGolden(t *testing.T) {
+	for _, reclaim := range []bool{true, false} {
+		for _, fstype := range []string{"", "xfs"} {
+			for _, readOnly := range []bool{true, false} {
+				fsname := fstype
+				if len(fsname) == 0 {
+					fsname = "default"
+				}
+				name := fmt.Sprintf("reclaim-%v-fstype-%s-readonly-%v", reclaim, fsname, readOnly)
+				t.Run(name, func(t *testing.T) {
+					volume := newBlockVolume("vol1", reclaim)
+					volume.Spec.FSType = fstype
+					volume.Spec.ReadOnly = readOnly
+
+					out, err := StorageClassYAML(createBlockStorageClass(volume, "rook-ceph"))
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					golden := filepath.Join("testdata", "storageclass-"+name+".yaml")
+					if *update {
+						if err = ioutil.WriteFile(golden, out, 0644); err != nil {
+							t.Fatalf("failed to update golden file: %v", err)
+						}
+					}
+					expected, err := ioutil.ReadFile(golden)
+					if err != nil {
+						t.Fatalf("failed to read golden file: %v", err)
+					}
+					if string(out) != string(expected) {
+						t.Errorf("storage class does not match %s:\n%s", golden, out)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestStorageClassSerializationRoundTrip(t *testing.T) {
+	volume := newBlockVolume("vol1", true)
+	volume.Spec.PoolID = "pool: \"odd\"\n- name"
+	volume.Spec.ClusterID = "#cluster"
+	class := createBlockStorageClass(volume, "rook-ceph")
+
+	for name, serialize := range map[string]func(*storagev1.StorageClass) ([]byte, error){
+		"yaml": StorageClassYAML,
+		"json": StorageClassJSON,
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, err := serialize(class)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			decoded := &storagev1.StorageClass{}
+			if err = yaml.Unmarshal(out, decoded); err != nil {
+				t.Fatalf("failed to decode %s: %v", out, err)
+			}
+			if decoded.Parameters["pool"] != volume.Spec.PoolID {
+				t.Errorf("expected pool %q, got %q", volume.Spec.PoolID, decoded.Parameters["pool"])
+			}
+			if decoded.Parameters["clusterID"] != volume.Spec.ClusterID {
+				t.Errorf("expected clusterID %q, got %q", volume.Spec.ClusterID, decoded.Parameters["clusterID"])
+			}
+			if decoded.Kind != "StorageClass" || decoded.APIVersion != "storage.k8s.io/v1" {
+				t.Errorf("unexpected type meta %+v", decoded.TypeMeta)
+			}
+		})
+	}
+}
+
+func TestStorageVolumesUpdateRecreateFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		message  string
+		restored bool
+	}{
+		{name: "previous class restored", failures: 1, message: "the previous storage class was restored", restored: true},
+		{name: "previous class lost", failures: 2, message: "was deleted and could not be recreated", restored: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset()
+			s := &StorageVolumes{Namespace: "rook-ceph", KubeClient: client}
+			if _, _, err := s.Create(newBlockVolume("vol1", true)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			failures := tt.failures
+			client.PrependReactor("create", "storageclasses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if failures == 0 {
+					return false, nil, nil
+				}
+				failures--
+				return true, nil, apierrors.NewInternalError(errors.New("create failed"))
+			})
+
+			_, err := s.Update(newBlockVolume("vol1", false))
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Fatalf("expected error containing %q, got %v", tt.message, err)
+			}
+			class, err := client.StorageV1().StorageClasses().Get(context.TODO(), "rook-ceph.vol1-block", metav1.GetOptions{})
+			if !tt.restored {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected the storage class to be deleted, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected the storage class to be restored, got %v", err)
+			}
+			if *class.ReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+				t.Errorf("expected the previous reclaim policy, got %s", *class.ReclaimPolicy)
+			}
+		})
+	}
+}
+
+func TestStorageVolumesNamespacedClass(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	for _, namespace := range []string{"rook-ceph", "other"} {
+		s := &StorageVolumes{Namespace: namespace, KubeClient: client}
+		volume := newBlockVolume("vol1", true)
+		volume.ObjectMeta.Namespace = namespace
+		if _, _, err := s.Create(volume); err != nil {
+			t.Fatalf("unexpected error creating %s/vol1: %v", namespace, err)
+		}
+	}
+
+	s := &StorageVolumes{Namespace: "other", KubeClient: client}
+	if err := s.Delete("vol1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s = &StorageVolumes{Namespace: "rook-ceph", KubeClient: client}
+	if _, err := s.Get("vol1"); err != nil {
+		t.Errorf("expected rook-ceph/vol1 to be kept, got %v", err)
+	}
+}
+
+func TestStorageVolumesDoneContext(t *testing.T) {
+	for name, ctx := range doneContexts() {
+		t.Run(name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset()
+			client.PrependReactor("*", "*", contextReactor(ctx))
+			s := &StorageVolumes{Namespace: "rook-ceph", KubeClient: client}
+
+			if _, _, err := s.CreateContext(ctx, newBlockVolume("vol1", true)); !errors.Is(err, ctx.Err()) {
+				t.Errorf("expected %v from create, got %v", ctx.Err(), err)
+			}
+			if _, err := s.UpdateContext(ctx, newBlockVolume("vol1", false)); !errors.Is(err, ctx.Err()) {
+				t.Errorf("expected %v from update, got %v", ctx.Err(), err)
+			}
+			_, err := client.Tracker().Get(storagev1.SchemeGroupVersion.WithResource("storageclasses"), "", "rook-ceph.vol1-block")
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("expected no storage class, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseStorageClassName(t *testing.T) {
+	tests := []struct {
+		class     string
+		namespace string
+		volume    string
+		ok        bool
+	}{
+		{class: StorageClassName("rook-ceph", "vol1"), namespace: "rook-ceph", volume: "vol1", ok: true},
+		{class: StorageClassName("apps", "db.data-block"), namespace: "apps", volume: "db.data-block", ok: true},
+		{class: "vol1-block"},
+		{class: "rook-ceph.-block"},
+		{class: "standard"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			namespace, volume, ok := ParseStorageClassName(tt.class)
+			if namespace != tt.namespace || volume != tt.volume || ok != tt.ok {
+				t.Errorf("expected %q %q %v, got %q %q %v", tt.namespace, tt.volume, tt.ok, namespace, volume, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	defaultWaitTimeout  time.Duration = 10 * time.Minute
+	defaultPollInterval time.Duration = 10 * time.Second
+)
+
+// watchRestartBackoff spaces the restarts of a watch the server keeps
+// closing, it is reset once a watch delivers an event.
+var watchRestartBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.5,
+	Steps:    8,
+	Cap:      30 * time.Second,
+}
+
+// WaitStatus is the last observed status of the object being waited on.
+type WaitStatus struct {
+	Phase   string
+	State   string
+	Message string
+}
+
+// WaitOptions configures the WaitFor helpers.
+type WaitOptions struct {
+	// Timeout of the wait, defaults to 10 minutes.
+	Timeout time.Duration
+
+	// Interval between two Gets when the object cannot be watched,
+	// defaults to 10 seconds.
+	PollInterval time.Duration
+
+	// Progress, if set, is called every time the observed status changes.
+	Progress func(status WaitStatus)
+}
+
+// WaitTimeoutError is returned when the wait times out or its context is
+// cancelled, it carries the last observed status of the object.
+type WaitTimeoutError struct {
+	Kind   string
+	Name   string
+	Status WaitStatus
+	Err    error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out waiting for %s %s, phase %q state %q message %q: %v",
+		e.Kind, e.Name, e.Status.Phase, e.Status.State, e.Status.Message, e.Err)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// WaitFailedError is returned as soon as the object being waited on
+// reaches the Failure phase, it carries the status of the object.
+type WaitFailedError struct {
+	Kind   string
+	Name   string
+	Status WaitStatus
+}
+
+func (e *WaitFailedError) Error() string {
+	return fmt.Sprintf("Failed waiting for %s %s, phase %q state %q message %q",
+		e.Kind, e.Name, e.Status.Phase, e.Status.State, e.Status.Message)
+}
+
+// waitCondition evaluates an observed object, obj is nil once the object
+// does not exist.
+type waitCondition func(obj runtime.Object) (WaitStatus, bool)
+
+type waiter struct {
+	kind      string
+	name      string
+	opts      WaitOptions
+	get       func(ctx context.Context) (runtime.Object, error)
+	watch     func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	condition waitCondition
+	// failed reports the terminal statuses, nil if there are none.
+	failed func(status WaitStatus) bool
+	// backoff between watch restarts, watchRestartBackoff if zero.
+	backoff wait.Backoff
+
+	last     WaitStatus
+	observed bool
+}
+
+// observe evaluates obj and reports progress if the status changed, a
+// terminal status is returned as a WaitFailedError.
+func (w *waiter) observe(obj runtime.Object) (bool, error) {
+	status, done := w.condition(obj)
+	if !w.observed || status != w.last {
+		w.observed = true
+		w.last = status
+		if w.opts.Progress != nil {
+			w.opts.Progress(status)
+		}
+	}
+	if !done && w.failed != nil && w.failed(status) {
+		return false, &WaitFailedError{Kind: w.kind, Name: w.name, Status: status}
+	}
+	return done, nil
+}
+
+// check gets the object and evaluates it, returning its resource version
+// to start watching from.
+func (w *waiter) check(ctx context.Context) (bool, string, error) {
+	obj, err := w.get(ctx)
+	if apierrors.IsNotFound(err) {
+		done, err := w.observe(nil)
+		return done, "", err
+	} else if err != nil {
+		return false, "", err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false, "", err
+	}
+	done, err := w.observe(obj)
+	return done, accessor.GetResourceVersion(), err
+}
+
+func (w *waiter) timeout(ctx context.Context) error {
+	return &WaitTimeoutError{
+		Kind:   w.kind,
+		Name:   w.name,
+		Status: w.last,
+		Err:    ctx.Err(),
+	}
+}
+
+// poll gets the object every PollInterval, used when it cannot be watched.
+func (w *waiter) poll(ctx context.Context) error {
+	interval := w.opts.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return w.timeout(ctx)
+		case <-ticker.C:
+		}
+		done, _, err := w.check(ctx)
+		if ctx.Err() != nil {
+			return w.timeout(ctx)
+		} else if err != nil {
+			return err
+		} else if done {
+			return nil
+		}
+	}
+}
+
+// wait watches the object until the condition is met, the watch is
+// restarted with a backoff when the server closes it and polling is used
+// if the object cannot be watched at all.
+func (w *waiter) wait(ctx context.Context) error {
+	timeout := w.opts.Timeout
+	if timeout == 0 {
+		timeout = defaultWaitTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	backoff := w.backoff
+	if backoff.Duration == 0 {
+		backoff = watchRestartBackoff
+	}
+	restart := backoff
+
+	for {
+		done, resourceVersion, err := w.check(ctx)
+		if ctx.Err() != nil {
+			return w.timeout(ctx)
+		} else if err != nil {
+			return err
+		} else if done {
+			return nil
+		}
+
+		watcher, err := w.watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", w.name).String(),
+			ResourceVersion: resourceVersion,
+		})
+		if ctx.Err() != nil {
+			return w.timeout(ctx)
+		} else if err != nil {
+			return w.poll(ctx)
+		}
+		done, received, err := w.consume(ctx, watcher)
+		watcher.Stop()
+		if err != nil || done {
+			return err
+		}
+
+		if received {
+			restart = backoff
+		}
+		select {
+		case <-ctx.Done():
+			return w.timeout(ctx)
+		case <-time.After(restart.Step()):
+		}
+	}
+}
+
+// consume handles watch events until the condition is met or the watch
+// ends, in which case the caller restarts it. It also reports whether the
+// watch delivered any event.
+func (w *waiter) consume(ctx context.Context, watcher watch.Interface) (bool, bool, error) {
+	received := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, received, w.timeout(ctx)
+		case event, ok := <-watcher.ResultChan():
+			if !ok || event.Type == watch.Error {
+				return false, received, nil
+			}
+			received = true
+			accessor, err := meta.Accessor(event.Object)
+			if err != nil || accessor.GetName() != w.name {
+				continue
+			}
+			obj := event.Object
+			if event.Type == watch.Deleted {
+				obj = nil
+			}
+			done, err := w.observe(obj)
+			if err != nil || done {
+				return done, received, err
+			}
+		}
+	}
+}
+
+func isClusterReady(cluster *StorageCluster) bool {
+	if cluster.Status.Phase == ClusterPhaseReady && cluster.Status.State == ClusterStateCreated {
+		return true
+	}
+	// External clusters only connect to the storage cluster.
+	return cluster.Status.Phase == ClusterPhaseConnected && cluster.Status.State == ClusterStateConnected
+}
+
+// WaitForClusterReady waits until the storage cluster is ready, or
+// connected for an external cluster. It returns a WaitFailedError as soon
+// as the cluster is in the Failure phase.
+func (c *StorageClusters) WaitForClusterReady(ctx context.Context, clustername string, opts WaitOptions) (*StorageCluster, error) {
+	var cluster *StorageCluster
+
+	cephclusters := c.Client.CephV1().CephClusters(c.Namespace)
+	w := &waiter{
+		kind: "storage cluster",
+		name: clustername,
+		opts: opts,
+		get: func(ctx context.Context) (runtime.Object, error) {
+			return cephclusters.Get(ctx, clustername, metav1.GetOptions{})
+		},
+		watch: cephclusters.Watch,
+		condition: func(obj runtime.Object) (WaitStatus, bool) {
+			cephcluster, ok := obj.(*cephv1.CephCluster)
+			if !ok {
+				return WaitStatus{}, false
+			}
+			cluster = newStorageCluster(cephcluster)
+			status := WaitStatus{
+				Phase:   string(cluster.Status.Phase),
+				State:   string(cluster.Status.State),
+				Message: cluster.Status.Message,
+			}
+			return status, isClusterReady(cluster)
+		},
+		failed: func(status WaitStatus) bool {
+			return status.Phase == string(ClusterPhaseFailure)
+		},
+	}
+	err := w.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// WaitForPoolReady waits until the storage pool is ready. It returns a
+// WaitFailedError as soon as the pool is in the Failure phase.
+func (p *StoragePools) WaitForPoolReady(ctx context.Context, poolname string, opts WaitOptions) (*StoragePool, error) {
+	var blockpool *StoragePool
+
+	cephblockpools := p.Client.CephV1().CephBlockPools(p.Namespace)
+	w := &waiter{
+		kind: "storage pool",
+		name: poolname,
+		opts: opts,
+		get: func(ctx context.Context) (runtime.Object, error) {
+			return cephblockpools.Get(ctx, poolname, metav1.GetOptions{})
+		},
+		watch: cephblockpools.Watch,
+		condition: func(obj runtime.Object) (WaitStatus, bool) {
+			pool, ok := obj.(*cephv1.CephBlockPool)
+			if !ok {
+				return WaitStatus{}, false
+			}
+			blockpool = p.newStoragePool(pool)
+			return WaitStatus{Phase: string(blockpool.Status.Phase)}, blockpool.Status.Phase == PoolPhaseReady
+		},
+		failed: func(status WaitStatus) bool {
+			return status.Phase == string(PoolPhaseFailure)
+		},
+	}
+	err := w.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return blockpool, nil
+}
+
+// WaitForPoolDeleted waits until the storage pool no longer exists.
+func (p *StoragePools) WaitForPoolDeleted(ctx context.Context, poolname string, opts WaitOptions) error {
+	cephblockpools := p.Client.CephV1().CephBlockPools(p.Namespace)
+	w := &waiter{
+		kind: "storage pool",
+		name: poolname,
+		opts: opts,
+		get: func(ctx context.Context) (runtime.Object, error) {
+			return cephblockpools.Get(ctx, poolname, metav1.GetOptions{})
+		},
+		watch: cephblockpools.Watch,
+		condition: func(obj runtime.Object) (WaitStatus, bool) {
+			pool, ok := obj.(*cephv1.CephBlockPool)
+			if !ok {
+				return WaitStatus{}, true
+			}
+			return WaitStatus{Phase: string(mapPoolPhase(pool))}, false
+		},
+	}
+	return w.wait(ctx)
+}
@@ -0,0 +1,247 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// withFakeWatch makes watches of resource return a fake watcher, which is
+// sent on the returned channel once the wait started watching.
+func withFakeWatch(client *rookfake.Clientset, resource string) chan *watch.FakeWatcher {
+	watchers := make(chan *watch.FakeWatcher, 1)
+	client.PrependWatchReactor(resource, func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher := watch.NewFake()
+		watchers <- watcher
+		return true, watcher, nil
+	})
+	return watchers
+}
+
+func TestWaitForClusterReadyAlreadyReady(t *testing.T) {
+	client := rookfake.NewSimpleClientset(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	cluster, err := c.WaitForClusterReady(context.Background(), "c1", WaitOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Status.Phase != ClusterPhaseReady {
+		t.Errorf("expected phase Ready, got %s", cluster.Status.Phase)
+	}
+}
+
+func TestWaitForClusterReadyWatch(t *testing.T) {
+	client := rookfake.NewSimpleClientset(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionProgressing, cephv1.ClusterStateCreating))
+	watchers := withFakeWatch(client, "cephclusters")
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	var phases []string
+	go func() {
+		watcher := <-watchers
+		watcher.Modify(newCephCluster("other", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+		watcher.Modify(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionProgressing, cephv1.ClusterStateCreating))
+		watcher.Modify(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+	}()
+	cluster, err := c.WaitForClusterReady(context.Background(), "c1", WaitOptions{
+		Timeout: 10 * time.Second,
+		Progress: func(status WaitStatus) {
+			phases = append(phases, status.Phase)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.Name != "c1" || cluster.Status.Phase != ClusterPhaseReady {
+		t.Errorf("unexpected cluster %s phase %s", cluster.Name, cluster.Status.Phase)
+	}
+	expected := []string{"Progressing", "Ready"}
+	if fmt.Sprint(phases) != fmt.Sprint(expected) {
+		t.Errorf("expected progress %v, got %v", expected, phases)
+	}
+}
+
+func TestWaitForPoolReadyTimeout(t *testing.T) {
+	pool := newErasureCodedBlockPool("bpool1", "rook-ceph", 3, 2, "host", "hdd")
+	client := rookfake.NewSimpleClientset(pool)
+	withFakeWatch(client, "cephblockpools")
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	_, err := p.WaitForPoolReady(context.Background(), "bpool1", WaitOptions{Timeout: 50 * time.Millisecond})
+	var timeout *WaitTimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if timeout.Status.Phase != string(PoolPhaseConnecting) {
+		t.Errorf("expected last phase Connecting, got %s", timeout.Status.Phase)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestWaitForPoolReadyPollingFallback(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	client.PrependWatchReactor("cephblockpools", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, fmt.Errorf("watch is forbidden")
+	})
+	gets := 0
+	client.PrependReactor("get", "cephblockpools", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gets++
+		if gets < 3 {
+			return true, newErasureCodedBlockPool("bpool1", "rook-ceph", 3, 2, "host", "hdd"), nil
+		}
+		return true, newReplicatedBlockPool("bpool1", "rook-ceph", 3, "host", "hdd"), nil
+	})
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	blockpool, err := p.WaitForPoolReady(context.Background(), "bpool1", WaitOptions{
+		Timeout:      10 * time.Second,
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if blockpool.Status.Phase != PoolPhaseReady {
+		t.Errorf("expected phase Ready, got %s", blockpool.Status.Phase)
+	}
+	if gets != 3 {
+		t.Errorf("expected 3 gets, got %d", gets)
+	}
+}
+
+func TestWaitForPoolDeleted(t *testing.T) {
+	pool := newReplicatedBlockPool("bpool1", "rook-ceph", 3, "host", "hdd")
+	client := rookfake.NewSimpleClientset(pool)
+	watchers := withFakeWatch(client, "cephblockpools")
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	go func() {
+		watcher := <-watchers
+		watcher.Delete(pool)
+	}()
+	err := p.WaitForPoolDeleted(context.Background(), "bpool1", WaitOptions{Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client = rookfake.NewSimpleClientset()
+	p = &StoragePools{Namespace: "rook-ceph", Client: client}
+	err = p.WaitForPoolDeleted(context.Background(), "bpool1", WaitOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error for missing pool: %v", err)
+	}
+	if len(client.Actions()) != 1 {
+		t.Errorf("expected a single get, got %v", client.Actions())
+	}
+}
+
+func TestWaitForClusterReadyCancelled(t *testing.T) {
+	client := rookfake.NewSimpleClientset(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionProgressing, cephv1.ClusterStateCreating))
+	withFakeWatch(client, "cephclusters")
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go cancel()
+	_, err := c.WaitForClusterReady(ctx, "c1", WaitOptions{})
+	var timeout *WaitTimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if timeout.Status.Message != "status of c1" {
+		t.Errorf("expected last message, got %q", timeout.Status.Message)
+	}
+}
+
+func TestWaitForReadyFailure(t *testing.T) {
+	t.Run("cluster already failed", func(t *testing.T) {
+		client := rookfake.NewSimpleClientset(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionFailure, cephv1.ClusterStateError))
+		c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+		_, err := c.WaitForClusterReady(context.Background(), "c1", WaitOptions{Timeout: 10 * time.Second})
+		var failed *WaitFailedError
+		if !errors.As(err, &failed) {
+			t.Fatalf("expected failed error, got %v", err)
+		}
+		if failed.Status.Phase != string(ClusterPhaseFailure) || failed.Status.Message != "status of c1" {
+			t.Errorf("expected the Failure status, got %+v", failed.Status)
+		}
+	})
+
+	t.Run("pool failing while watched", func(t *testing.T) {
+		pool := newErasureCodedBlockPool("bpool1", "rook-ceph", 3, 2, "host", "hdd")
+		client := rookfake.NewSimpleClientset(pool)
+		watchers := withFakeWatch(client, "cephblockpools")
+		p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+		go func() {
+			watcher := <-watchers
+			failed := pool.DeepCopy()
+			failed.Status.Phase = "Failure"
+			watcher.Modify(failed)
+		}()
+		_, err := p.WaitForPoolReady(context.Background(), "bpool1", WaitOptions{Timeout: 10 * time.Second})
+		var failed *WaitFailedError
+		if !errors.As(err, &failed) {
+			t.Fatalf("expected failed error, got %v", err)
+		}
+		if failed.Status.Phase != string(PoolPhaseFailure) {
+			t.Errorf("expected phase Failure, got %s", failed.Status.Phase)
+		}
+	})
+}
+
+func TestWaitWatchRestartBackoff(t *testing.T) {
+	client := rookfake.NewSimpleClientset(newErasureCodedBlockPool("bpool1", "rook-ceph", 3, 2, "host", "hdd"))
+	var watches []time.Time
+	client.PrependWatchReactor("cephblockpools", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watches = append(watches, time.Now())
+		watcher := watch.NewFake()
+		watcher.Stop()
+		return true, watcher, nil
+	})
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+	cephblockpools := client.CephV1().CephBlockPools("rook-ceph")
+	w := &waiter{
+		kind: "storage pool",
+		name: "bpool1",
+		opts: WaitOptions{Timeout: 500 * time.Millisecond},
+		get: func(ctx context.Context) (runtime.Object, error) {
+			return cephblockpools.Get(ctx, "bpool1", metav1.GetOptions{})
+		},
+		watch: cephblockpools.Watch,
+		condition: func(obj runtime.Object) (WaitStatus, bool) {
+			pool, ok := obj.(*cephv1.CephBlockPool)
+			if !ok {
+				return WaitStatus{}, false
+			}
+			return WaitStatus{Phase: string(p.newStoragePool(pool).Status.Phase)}, false
+		},
+		backoff: wait.Backoff{Duration: 20 * time.Millisecond, Factor: 2, Steps: 10},
+	}
+
+	var timeout *WaitTimeoutError
+	if err := w.wait(context.Background()); !errors.As(err, &timeout) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	// The restarts are 20, 40, 80 and 160ms apart, a closed watch restarted
+	// right away would be restarted hundreds of times.
+	if len(watches) < 3 || len(watches) > 6 {
+		t.Fatalf("expected 3 to 6 watches, got %d", len(watches))
+	}
+	for i := 1; i < len(watches); i++ {
+		if delay := watches[i].Sub(watches[i-1]); delay < 20*time.Millisecond<<uint(i-1) {
+			t.Errorf("expected restart %d after at least %v, got %v", i, 20*time.Millisecond<<uint(i-1), delay)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"context"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// StorageClusterEvent is a change to a storage cluster, Type is one of
+// watch.Added, watch.Modified, watch.Deleted or watch.Error.
+// Err is only set for watch.Error events.
+type StorageClusterEvent struct {
+	Type    watch.EventType
+	Cluster *StorageCluster
+	Err     error
+}
+
+// StoragePoolEvent is a change to a storage pool, Type is one of
+// watch.Added, watch.Modified, watch.Deleted or watch.Error.
+// Err is only set for watch.Error events.
+type StoragePoolEvent struct {
+	Type watch.EventType
+	Pool *StoragePool
+	Err  error
+}
+
+// Watch streams the changes to the storage clusters matching opts.
+// The channel is closed when ctx is done or the server ends the watch.
+func (c *StorageClusters) Watch(ctx context.Context, opts metav1.ListOptions) (<-chan StorageClusterEvent, error) {
+	watcher, err := c.Client.CephV1().CephClusters(c.Namespace).Watch(ctx, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	events := make(chan StorageClusterEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+		for {
+			var clusterevent StorageClusterEvent
+
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				clusterevent.Type = event.Type
+				if event.Type == watch.Error {
+					clusterevent.Err = wrapError(apierrors.FromObject(event.Object))
+				} else if cephcluster, ok := event.Object.(*cephv1.CephCluster); ok {
+					clusterevent.Cluster = newStorageCluster(cephcluster)
+				} else {
+					continue
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- clusterevent:
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Watch streams the changes to the storage pools matching opts.
+// The channel is closed when ctx is done or the server ends the watch.
+func (p *StoragePools) Watch(ctx context.Context, opts metav1.ListOptions) (<-chan StoragePoolEvent, error) {
+	watcher, err := p.Client.CephV1().CephBlockPools(p.Namespace).Watch(ctx, opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	events := make(chan StoragePoolEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+		for {
+			var poolevent StoragePoolEvent
+
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				poolevent.Type = event.Type
+				if event.Type == watch.Error {
+					poolevent.Err = wrapError(apierrors.FromObject(event.Object))
+				} else if pool, ok := event.Object.(*cephv1.CephBlockPool); ok {
+					poolevent.Pool = p.newStoragePool(pool)
+				} else {
+					continue
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- poolevent:
+			}
+		}
+	}()
+	return events, nil
+}
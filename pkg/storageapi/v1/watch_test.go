@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestStorageClustersWatch(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	watchers := withFakeWatch(client, "cephclusters")
+	c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := c.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	watcher := <-watchers
+	go func() {
+		watcher.Add(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionProgressing, cephv1.ClusterStateCreating))
+		watcher.Modify(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+		watcher.Error(&apierrors.NewGone("too old resource version").ErrStatus)
+		watcher.Delete(newCephCluster("c1", "rook-ceph", nil, cephv1.ConditionReady, cephv1.ClusterStateCreated))
+		watcher.Stop()
+	}()
+
+	tests := []struct {
+		eventType watch.EventType
+		phase     StorageClusterPhase
+		state     StorageClusterState
+	}{
+		{watch.Added, ClusterPhaseProgressing, ClusterStateCreating},
+		{watch.Modified, ClusterPhaseReady, ClusterStateCreated},
+		{watch.Error, "", ""},
+		{watch.Deleted, ClusterPhaseReady, ClusterStateCreated},
+	}
+	for _, tt := range tests {
+		event, ok := <-events
+		if !ok {
+			t.Fatalf("channel closed, expected %s event", tt.eventType)
+		}
+		if event.Type != tt.eventType {
+			t.Fatalf("expected %s event, got %s", tt.eventType, event.Type)
+		}
+		if tt.eventType == watch.Error {
+			if !apierrors.IsGone(event.Err) {
+				t.Errorf("expected gone error, got %v", event.Err)
+			}
+			continue
+		}
+		if event.Cluster.Name != "c1" || event.Cluster.Status.Phase != tt.phase || event.Cluster.Status.State != tt.state {
+			t.Errorf("%s: unexpected cluster %s phase %s state %s", tt.eventType,
+				event.Cluster.Name, event.Cluster.Status.Phase, event.Cluster.Status.State)
+		}
+	}
+	if _, ok := <-events; ok {
+		t.Errorf("expected channel closed once the watch stopped")
+	}
+}
+
+func TestStoragePoolsWatch(t *testing.T) {
+	client := rookfake.NewSimpleClientset()
+	watchers := withFakeWatch(client, "cephblockpools")
+	p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := p.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	watcher := <-watchers
+	go watcher.Add(newReplicatedBlockPool("bpool1", "rook-ceph", 3, "host", "ssd"))
+
+	event := <-events
+	if event.Type != watch.Added {
+		t.Fatalf("expected Added event, got %s", event.Type)
+	}
+	pool := event.Pool
+	if pool.Name != "bpool1" || pool.Status.Phase != PoolPhaseReady {
+		t.Errorf("unexpected pool %s phase %s", pool.Name, pool.Status.Phase)
+	}
+	if pool.Spec.DurabilityPolicy.DurabilityClass != DurabilityClassReplicated ||
+		pool.Spec.PerfPolicy.IoPerfClass != DevMedium {
+		t.Errorf("unexpected pool spec %+v", pool.Spec)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("expected channel closed once the context is cancelled")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("channel not closed after the context was cancelled")
+	}
+	if !watcher.IsStopped() {
+		t.Errorf("expected the Ceph watch stopped")
+	}
+}
+
+func TestWatchError(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(err error) bool
+	}{
+		{
+			name:  "forbidden",
+			err:   apierrors.NewForbidden(schema.GroupResource{Resource: "cephblockpools"}, "", nil),
+			check: apierrors.IsForbidden,
+		},
+		{
+			name:  "not found",
+			err:   apierrors.NewNotFound(schema.GroupResource{Resource: "cephblockpools"}, ""),
+			check: func(err error) bool { return errors.Is(err, ErrNotFound) && apierrors.IsNotFound(err) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := rookfake.NewSimpleClientset()
+			client.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				return true, nil, tt.err
+			})
+			c := &StorageClusters{Namespace: "rook-ceph", Client: client}
+			p := &StoragePools{Namespace: "rook-ceph", Client: client}
+
+			if _, err := c.Watch(context.Background(), metav1.ListOptions{}); !tt.check(err) {
+				t.Errorf("unexpected error from the storage cluster watch: %v", err)
+			}
+			if _, err := p.Watch(context.Background(), metav1.ListOptions{}); !tt.check(err) {
+				t.Errorf("unexpected error from the storage pool watch: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Package webhook serves the admission webhooks of the storage.rookclient.io
+// objects, defaulting their specs on /mutate and rejecting invalid specs and
+// unsafe updates on /validate.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	MutatePath   string = "/mutate"
+	ValidatePath string = "/validate"
+
+	// Largest admission review accepted, the API server sends at most 3MB
+	// objects.
+	maxReviewSize int64 = 6 * 1024 * 1024
+)
+
+// specDefault is the default of the spec field at path, applied when the
+// field is absent from the admitted object.
+type specDefault struct {
+	path  []string
+	value interface{}
+}
+
+// Defaults documented on the storage API types.
+var specDefaults = map[string][]specDefault{
+	"StorageCluster": {
+		{path: []string{"spec", "monitoring"}, value: true},
+	},
+	"StoragePool": {
+		{path: []string{"spec", "durabilitypolicy", "failuredomain"}, value: storageapiv1.DefaultFailureDomain},
+		{path: []string{"spec", "durabilitypolicy", "durabilityclass"}, value: storageapiv1.DefaultDurabilityClass},
+		{path: []string{"spec", "durabilitypolicy", "redundancylevel"}, value: storageapiv1.DefaultDurabilityLevel},
+	},
+	"StorageVolume": {
+		{path: []string{"spec", "fstype"}, value: storageapiv1.DefaultFSType},
+		{path: []string{"spec", "reclaim"}, value: true},
+		{path: []string{"spec", "allowexpansion"}, value: true},
+	},
+}
+
+// patchOperation is a JSON patch operation, RFC 6902.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// NewHandler returns the handler serving the mutating webhook on
+// MutatePath and the validating webhook on ValidatePath. Storage pools are
+// validated against the mappings of profile, nil is the default profile.
+func NewHandler(profile *storageapiv1.MappingProfile) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(MutatePath, admissionHandler(mutate))
+	mux.Handle(ValidatePath, admissionHandler(func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		return validate(req, profile)
+	}))
+	return mux
+}
+
+// admissionHandler decodes the admission review of the request and
+// answers it with the response of admit.
+func admissionHandler(admit func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxReviewSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to read admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("Invalid admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to encode admission review: %v", err), http.StatusInternalServerError)
+		}
+	})
+}
+
+func denied(status *metav1.Status) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: false, Result: status}
+}
+
+func badRequest(err error) *admissionv1.AdmissionResponse {
+	return denied(&apierrors.NewBadRequest(err.Error()).ErrStatus)
+}
+
+// jsonPointer escapes the path segments into a JSON pointer, RFC 6901.
+func jsonPointer(path []string) string {
+	pointer := ""
+	for _, segment := range path {
+		pointer += "/" + escapePointer(segment)
+	}
+	return pointer
+}
+
+func escapePointer(segment string) string {
+	escaped := ""
+	for _, c := range segment {
+		switch c {
+		case '~':
+			escaped += "~0"
+		case '/':
+			escaped += "~1"
+		default:
+			escaped += string(c)
+		}
+	}
+	return escaped
+}
+
+// nestedValue returns value nested in the objects of the path.
+func nestedValue(path []string, value interface{}) interface{} {
+	for i := len(path) - 1; i >= 0; i-- {
+		value = map[string]interface{}{path[i]: value}
+	}
+	return value
+}
+
+// defaultPatch returns the operation adding value at path if the field is
+// absent or empty, the missing parents are added along with it.
+func defaultPatch(obj map[string]interface{}, path []string, value interface{}) *patchOperation {
+	current := obj
+	for i, key := range path {
+		child, ok := current[key]
+		if !ok || child == nil || child == "" {
+			current[key] = nestedValue(path[i+1:], value)
+			return &patchOperation{Op: "add", Path: jsonPointer(path[:i+1]), Value: nestedValue(path[i+1:], value)}
+		}
+		if i == len(path)-1 {
+			return nil
+		}
+		next, ok := child.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return nil
+}
+
+// mutate sets the documented defaults of the spec of the admitted object.
+func mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	defaults, ok := specDefaults[req.Kind.Kind]
+	if !ok || len(req.Object.Raw) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return badRequest(fmt.Errorf("Failed to decode %s: %v", req.Kind.Kind, err))
+	}
+
+	var patch []patchOperation
+	for _, d := range defaults {
+		if op := defaultPatch(obj, d.path, d.value); op != nil {
+			patch = append(patch, *op)
+		}
+	}
+	if len(patch) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	out, err := json.Marshal(patch)
+	if err != nil {
+		return badRequest(err)
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{Allowed: true, Patch: out, PatchType: &patchType}
+}
+
+// validateObject decodes the new and old objects of the request and
+// returns the errors of the new spec, with the unsafe changes from the old
+// one on update.
+func validateObject(req *admissionv1.AdmissionRequest, profile *storageapiv1.MappingProfile) (string, field.ErrorList, error) {
+	update := req.Operation == admissionv1.Update && len(req.OldObject.Raw) != 0
+	switch req.Kind.Kind {
+	case "StorageCluster":
+		var cluster, old storageapiv1.StorageCluster
+		if err := decode(req, &cluster, &old); err != nil {
+			return "", nil, err
+		}
+		if cluster.ObjectMeta.DeletionTimestamp != nil {
+			return cluster.ObjectMeta.Name, nil, nil
+		} else if update {
+			return cluster.ObjectMeta.Name, cluster.Spec.ValidateUpdate(&old.Spec), nil
+		}
+		return cluster.ObjectMeta.Name, cluster.Spec.Validate(), nil
+	case "StoragePool":
+		var pool, old storageapiv1.StoragePool
+		if err := decode(req, &pool, &old); err != nil {
+			return "", nil, err
+		}
+		if pool.ObjectMeta.DeletionTimestamp != nil {
+			return pool.ObjectMeta.Name, nil, nil
+		} else if update {
+			return pool.ObjectMeta.Name, pool.Spec.ValidateUpdate(&old.Spec, profile), nil
+		}
+		return pool.ObjectMeta.Name, pool.Spec.Validate(profile), nil
+	case "StorageVolume":
+		var volume, old storageapiv1.StorageVolume
+		if err := decode(req, &volume, &old); err != nil {
+			return "", nil, err
+		}
+		if volume.ObjectMeta.DeletionTimestamp != nil {
+			return volume.ObjectMeta.Name, nil, nil
+		} else if update {
+			return volume.ObjectMeta.Name, volume.Spec.ValidateUpdate(&old.Spec), nil
+		}
+		return volume.ObjectMeta.Name, volume.Spec.Validate(), nil
+	}
+	return "", nil, nil
+}
+
+func decode(req *admissionv1.AdmissionRequest, obj interface{}, old interface{}) error {
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return fmt.Errorf("Failed to decode %s: %v", req.Kind.Kind, err)
+	}
+	if len(req.OldObject.Raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+		return fmt.Errorf("Failed to decode old %s: %v", req.Kind.Kind, err)
+	}
+	return nil
+}
+
+// validate rejects the objects with an invalid spec and the updates
+// changing a spec field that cannot be changed in place.
+func validate(req *admissionv1.AdmissionRequest, profile *storageapiv1.MappingProfile) *admissionv1.AdmissionResponse {
+	if len(req.Object.Raw) == 0 {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	name, errs, err := validateObject(req, profile)
+	if err != nil {
+		return badRequest(err)
+	}
+	if len(errs) != 0 {
+		groupKind := storageapiv1.SchemeGroupVersion.WithKind(req.Kind.Kind).GroupKind()
+		return denied(&apierrors.NewInvalid(groupKind, name, errs).ErrStatus)
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
@@ -0,0 +1,284 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	storageapiv1 "github.com/murali-bashyam/rookclient/pkg/storageapi/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	poolJSON string = `{"apiVersion":"storage.rookclient.io/v1","kind":"StoragePool",` +
+		`"metadata":{"name":"bpool1","namespace":"rook-ceph"},"spec":{"clusterid":"rook-ceph"}}`
+	ecPoolJSON string = `{"apiVersion":"storage.rookclient.io/v1","kind":"StoragePool",` +
+		`"metadata":{"name":"bpool1","namespace":"rook-ceph"},"spec":{"clusterid":"rook-ceph",` +
+		`"durabilitypolicy":{"failuredomain":"host","durabilityclass":"erasurecoded","redundancylevel":"normal"}}}`
+	volumeJSON string = `{"apiVersion":"storage.rookclient.io/v1","kind":"StorageVolume",` +
+		`"metadata":{"name":"vol1","namespace":"rook-ceph"},` +
+		`"spec":{"volumetype":"block","clusterid":"rook-ceph","pool":"bpool1","fstype":"xfs","reclaim":false}}`
+	invalidVolumeJSON string = `{"apiVersion":"storage.rookclient.io/v1","kind":"StorageVolume",` +
+		`"metadata":{"name":"vol1","namespace":"rook-ceph"},"spec":{"volumetype":"file","clusterid":"rook-ceph"}}`
+)
+
+// admit posts an admission review of the object to the path of the
+// webhook server and returns the response.
+func admit(t *testing.T, server *httptest.Server, path string, kind string, operation admissionv1.Operation,
+	object string, old string) *admissionv1.AdmissionResponse {
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("4b6e2c39"),
+			Kind:      metav1.GroupVersionKind{Group: "storage.rookclient.io", Version: "v1", Kind: kind},
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: []byte(object)},
+		},
+	}
+	if len(old) != 0 {
+		review.Request.OldObject = runtime.RawExtension{Raw: []byte(old)}
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	review = admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if review.Response == nil || review.Response.UID != "4b6e2c39" {
+		t.Fatalf("expected a response to request 4b6e2c39, got %+v", review.Response)
+	}
+	return review.Response
+}
+
+func TestMutate(t *testing.T) {
+	server := httptest.NewServer(NewHandler(nil))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		kind   string
+		object string
+		patch  []patchOperation
+	}{
+		{
+			name:   "pool without durability policy",
+			kind:   "StoragePool",
+			object: poolJSON,
+			patch: []patchOperation{
+				{Op: "add", Path: "/spec/durabilitypolicy", Value: map[string]interface{}{"failuredomain": "host"}},
+				{Op: "add", Path: "/spec/durabilitypolicy/durabilityclass", Value: "replicated"},
+				{Op: "add", Path: "/spec/durabilitypolicy/redundancylevel", Value: "normal"},
+			},
+		},
+		{
+			name:   "pool with durability policy",
+			kind:   "StoragePool",
+			object: ecPoolJSON,
+		},
+		{
+			name:   "volume",
+			kind:   "StorageVolume",
+			object: volumeJSON,
+			patch:  []patchOperation{{Op: "add", Path: "/spec/allowexpansion", Value: true}},
+		},
+		{
+			name:   "cluster",
+			kind:   "StorageCluster",
+			object: `{"metadata":{"name":"rook-ceph"},"spec":{}}`,
+			patch:  []patchOperation{{Op: "add", Path: "/spec/monitoring", Value: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := admit(t, server, MutatePath, tt.kind, admissionv1.Create, tt.object, "")
+			if !resp.Allowed {
+				t.Fatalf("expected allowed, got %+v", resp.Result)
+			}
+			var patch []patchOperation
+			if len(resp.Patch) != 0 {
+				if resp.PatchType == nil || *resp.PatchType != admissionv1.PatchTypeJSONPatch {
+					t.Errorf("expected a JSONPatch, got %v", resp.PatchType)
+				}
+				if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(patch, tt.patch) {
+				t.Errorf("expected patch %+v, got %+v", tt.patch, patch)
+			}
+		})
+	}
+}
+
+func TestMutateTypedFalse(t *testing.T) {
+	server := httptest.NewServer(NewHandler(nil))
+	defer server.Close()
+	disabled := false
+
+	tests := []struct {
+		name   string
+		kind   string
+		object interface{}
+	}{
+		{
+			name: "cluster without monitoring",
+			kind: "StorageCluster",
+			object: &storageapiv1.StorageCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph", Namespace: "rook-ceph"},
+				Spec:       storageapiv1.StorageClusterSpec{Monitoring: &disabled},
+			},
+		},
+		{
+			name: "volume without reclaim",
+			kind: "StorageVolume",
+			object: &storageapiv1.StorageVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "vol1", Namespace: "rook-ceph"},
+				Spec: storageapiv1.StorageVolumeSpec{
+					VolumeType:     storageapiv1.BlockVolume,
+					ClusterID:      "rook-ceph",
+					PoolID:         "bpool1",
+					FSType:         "xfs",
+					Reclaim:        &disabled,
+					AllowExpansion: &disabled,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := json.Marshal(tt.object)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp := admit(t, server, MutatePath, tt.kind, admissionv1.Create, string(object), "")
+			if !resp.Allowed {
+				t.Fatalf("expected allowed, got %+v", resp.Result)
+			}
+			if len(resp.Patch) != 0 {
+				t.Errorf("expected no patch of the explicit false, got %s", resp.Patch)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	server := httptest.NewServer(NewHandler(nil))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		kind      string
+		operation admissionv1.Operation
+		object    string
+		old       string
+		allowed   bool
+		fields    []string
+	}{
+		{name: "valid volume", kind: "StorageVolume", operation: admissionv1.Create, object: volumeJSON, allowed: true},
+		{
+			name:      "invalid volume",
+			kind:      "StorageVolume",
+			operation: admissionv1.Create,
+			object:    invalidVolumeJSON,
+			fields:    []string{"spec.volumetype", "spec.pool"},
+		},
+		{
+			name:      "pool stored without defaults",
+			kind:      "StoragePool",
+			operation: admissionv1.Update,
+			object:    ecPoolJSON,
+			old:       poolJSON,
+			fields:    []string{"spec.durabilitypolicy.durabilityclass"},
+		},
+		{
+			name:      "durability class change",
+			kind:      "StoragePool",
+			operation: admissionv1.Update,
+			object:    ecPoolJSON,
+			old: `{"metadata":{"name":"bpool1"},"spec":{"clusterid":"rook-ceph",` +
+				`"durabilitypolicy":{"failuredomain":"host","durabilityclass":"replicated","redundancylevel":"normal"}}}`,
+			fields: []string{"spec.durabilitypolicy.durabilityclass"},
+		},
+		{
+			name:      "invalid volume being deleted",
+			kind:      "StorageVolume",
+			operation: admissionv1.Update,
+			object: `{"metadata":{"name":"vol1","deletionTimestamp":"2021-06-01T00:00:00Z"},` +
+				`"spec":{"volumetype":"file"}}`,
+			old:     invalidVolumeJSON,
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := admit(t, server, ValidatePath, tt.kind, tt.operation, tt.object, tt.old)
+			if resp.Allowed != tt.allowed {
+				t.Fatalf("expected allowed %v, got %+v", tt.allowed, resp.Result)
+			}
+			if tt.allowed {
+				return
+			}
+			if resp.Result == nil || resp.Result.Reason != metav1.StatusReasonInvalid || resp.Result.Details == nil {
+				t.Fatalf("expected an Invalid status, got %+v", resp.Result)
+			}
+			var fields []string
+			for _, cause := range resp.Result.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected fields %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	profile := storageapiv1.DefaultMappingProfile()
+	delete(profile.ErasureCoded, storageapiv1.DurabilityLevelNormal)
+	server := httptest.NewServer(NewHandler(profile))
+	defer server.Close()
+
+	replicatedPoolJSON := `{"metadata":{"name":"bpool1"},"spec":{"clusterid":"rook-ceph",` +
+		`"durabilitypolicy":{"failuredomain":"host","durabilityclass":"replicated","redundancylevel":"normal"}}}`
+	if resp := admit(t, server, ValidatePath, "StoragePool", admissionv1.Create, replicatedPoolJSON, ""); !resp.Allowed {
+		t.Errorf("expected the replicated pool allowed, got %+v", resp.Result)
+	}
+	resp := admit(t, server, ValidatePath, "StoragePool", admissionv1.Create, ecPoolJSON, "")
+	if resp.Allowed || resp.Result == nil || resp.Result.Details == nil || len(resp.Result.Details.Causes) != 1 ||
+		resp.Result.Details.Causes[0].Field != "spec.durabilitypolicy.redundancylevel" {
+		t.Errorf("expected the unmapped erasure coded level rejected, got %+v", resp.Result)
+	}
+}
+
+func TestAdmissionHandlerBadRequest(t *testing.T) {
+	server := httptest.NewServer(NewHandler(nil))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+ValidatePath, "application/json", bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}